@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tomakado/projector/internal/pkg/verbose"
+	projector "github.com/tomakado/projector/pkg"
+)
+
+var archiveExtensions = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+// checkArchivePath returns error if format of archive can't be detected by its extension.
+func checkArchivePath(path string) error {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(strings.ToLower(path), ext) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"unknown format of archive %q, supported extensions: %s",
+		path,
+		strings.Join(archiveExtensions, ", "),
+	)
+}
+
+// writeArchive writes content of dir into archive at path keeping modes of files.
+// Partially written archive is removed on failure.
+func writeArchive(path, dir string) (err error) {
+	if err := checkArchivePath(path); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("close archive: %w", closeErr)
+		}
+
+		if err != nil {
+			verbose.Printf("removing partially written archive %q", path)
+			_ = os.Remove(path)
+		}
+	}()
+
+	var (
+		gz     *gzip.Writer
+		output *projector.ArchiveOutput
+		lower  = strings.ToLower(path)
+	)
+
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		output = projector.NewZipOutput(f)
+	case strings.HasSuffix(lower, ".tar"):
+		output = projector.NewTarOutput(f)
+	default:
+		gz = gzip.NewWriter(f)
+		output = projector.NewTarOutput(gz)
+	}
+
+	verbose.Printf("archiving %q into %q", dir, path)
+	if err := output.AddDir(dir); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+
+	if err := output.Close(); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("write archive: %w", err)
+		}
+	}
+
+	return nil
+}
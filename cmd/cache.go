@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tomakado/projector/pkg/cache"
+)
+
+var (
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage local cache of templates fetched from remote sources",
+	}
+	cacheListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List cached templates",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheList,
+	}
+	cacheAddCmd = &cobra.Command{
+		Use:   "add [SOURCE...]",
+		Short: "Fetch templates into cache",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runCacheAdd,
+	}
+	cacheUpdateCmd = &cobra.Command{
+		Use:   "update [SOURCE...]",
+		Short: "Fetch cached templates again (all if no source passed)",
+		RunE:  runCacheUpdate,
+	}
+	cacheRemoveCmd = &cobra.Command{
+		Use:   "remove [SOURCE...]",
+		Short: "Remove templates from cache",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runCacheRemove,
+	}
+	cacheCleanCmd = &cobra.Command{
+		Use:   "clean",
+		Short: "Remove all cached templates",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheClean,
+	}
+)
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheAddCmd)
+	cacheCmd.AddCommand(cacheUpdateCmd)
+	cacheCmd.AddCommand(cacheRemoveCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+}
+
+func openCache() (*cache.Cache, error) {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return cache.New(dir), nil
+}
+
+func runCacheList(*cobra.Command, []string) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		fmt.Printf("%s\t%.12s\tfetched %s\n", e.Source, e.Revision, e.FetchedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	return nil
+}
+
+func runCacheAdd(cmd *cobra.Command, args []string) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	for _, source := range args {
+		e, err := c.Add(cmd.Context(), source)
+		if err != nil {
+			return fmt.Errorf("add %q: %w", source, err)
+		}
+
+		fmt.Printf("%s\t%.12s\n", e.Source, e.Revision)
+	}
+
+	return nil
+}
+
+func runCacheUpdate(cmd *cobra.Command, args []string) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	sources := args
+	if len(sources) == 0 {
+		entries, err := c.List()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			sources = append(sources, e.Source)
+		}
+	}
+
+	for _, source := range sources {
+		e, err := c.Update(cmd.Context(), source)
+		if err != nil {
+			return fmt.Errorf("update %q: %w", source, err)
+		}
+
+		fmt.Printf("%s\t%.12s\n", e.Source, e.Revision)
+	}
+
+	return nil
+}
+
+func runCacheRemove(_ *cobra.Command, args []string) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	for _, source := range args {
+		if err := c.Remove(source); err != nil {
+			return fmt.Errorf("remove %q: %w", source, err)
+		}
+	}
+
+	return nil
+}
+
+func runCacheClean(*cobra.Command, []string) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	return c.Clean()
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/tomakado/projector/internal/pkg/prompt"
 	"github.com/tomakado/projector/internal/pkg/verbose"
 	projector "github.com/tomakado/projector/pkg"
 	"github.com/tomakado/projector/pkg/manifest"
@@ -11,14 +16,26 @@ import (
 
 var (
 	createCmd = &cobra.Command{
-		Use:   "create [TEMPLATE]",
+		Use:   "create [TEMPLATE[+TEMPLATE...]] [DIRECTORY]",
 		Short: "Create project using specified template",
-		Args:  cobra.MinimumNArgs(1),
-		RunE:  runCreate,
+		Long: `Create project using specified template.
+
+Several templates can be applied onto the same directory one by one: join them with "+"
+(e.g. go/http+docker) or pass additional templates with --with flag.
+
+With --archive flag project is generated in temporary directory and written into tar.gz, tar
+or zip archive instead, directory argument must be omitted.`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: runCreate,
 	}
 	cfg             projector.Config
 	pathToManifest  string
 	includeAllSteps bool
+	rawVars         []string
+	noInput         bool
+	withTemplates   []string
+	answersPath     string
+	archivePath     string
 )
 
 func init() {
@@ -34,29 +51,344 @@ func init() {
 	createCmd.Flags().StringVarP(&pathToManifest, "manifest", "m", "", "path to custom template manifest")
 	createCmd.Flags().StringSliceVarP(&cfg.OptionalSteps, "include", "i", []string{}, "optional steps to include")
 	createCmd.Flags().BoolVar(&includeAllSteps, "all", false, "include all optional steps (overrides --include)")
+	createCmd.Flags().StringArrayVar(&rawVars, "var", []string{}, "template variable in key=value format (repeatable)")
+	createCmd.Flags().BoolVar(&noInput, "no-input", false, "never ask for missing values interactively")
+	createCmd.Flags().StringSliceVarP(&withTemplates, "with", "w", []string{}, "templates to apply after the main one")
+	createCmd.Flags().BoolVar(&cfg.DryRun, "dry-run", false, "print files and commands instead of generating project")
+	createCmd.Flags().BoolVar(&cfg.KeepOnFailure, "keep-on-failure", false, "keep partially generated project if generation fails")
+	createCmd.Flags().StringVar(&answersPath, "answers", "", "replay generation with answers file (e.g. "+projector.AnswersFilename+")")
+	createCmd.Flags().StringVar(&archivePath, "archive", "", "write project into archive (.tar.gz, .tgz, .tar or .zip)")
+	createCmd.Flags().StringVar(
+		&cfg.Overwrite,
+		"overwrite",
+		"",
+		"policy for existing files: "+strings.Join(manifest.OverwritePolicies, ", ")+
+			" (overrides policies of template files; default: policy of file or "+manifest.OverwriteFail+")",
+	)
+	addOutputFlag(createCmd)
 }
 
-func runCreate(_ *cobra.Command, args []string) error {
-	var p provider
-	if pathToManifest != "" {
+func runCreate(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+
+	if archivePath != "" {
+		if cfg.DryRun {
+			return fmt.Errorf("--archive can't be combined with --dry-run")
+		}
+
+		if err := checkArchivePath(archivePath); err != nil {
+			return err
+		}
+	}
+
+	var (
+		p         provider
+		source    string
+		revision  string
+		templates = withTemplates
+		vars      = map[string]string{}
+		archived  bool
+	)
+
+	if answersPath != "" {
+		a, err := projector.LoadAnswers(answersPath)
+		if err != nil {
+			return err
+		}
+
+		applyAnswers(cmd, a)
+
+		source = a.Templates[0].Source
+		for _, t := range a.Templates[1:] {
+			templates = append(templates, t.Source)
+		}
+		vars = a.RawVars()
+
+		main, err := loadTemplate(cmd.Context(), source, false)
+		if err != nil {
+			return err
+		}
+		p, pathToManifest, revision = main.Provider, main.PathToManifest, main.Revision
+
+		if cfg.WorkingDirectory, err = workingDirectoryArg(args, 0); err != nil {
+			return err
+		}
+		noInput = true
+	} else if pathToManifest != "" {
 		verbose.Printf("custom manifest filename passed: %q", pathToManifest)
 		p = manifest.NewRealFSProvider(filepath.Dir(pathToManifest))
-		cfg.WorkingDirectory = args[0]
+
+		var err error
+		if cfg.WorkingDirectory, err = workingDirectoryArg(args, 0); err != nil {
+			return err
+		}
+
+		if source, err = filepath.Abs(pathToManifest); err != nil {
+			return fmt.Errorf("resolve path to manifest: %w", err)
+		}
 	} else {
-		pathToManifest = args[0]
-		verbose.Printf("using manifest name %q in embed fs", pathToManifest)
-		p = manifest.NewEmbedFSProvider(&resources, embedRoot)
-		cfg.WorkingDirectory = args[1]
+		if len(args) == 0 {
+			return fmt.Errorf("template is required")
+		}
+
+		names := splitTemplates(args[0])
+		templates = append(names[1:], templates...)
+		source = templateSource(names[0])
+
+		main, err := loadTemplate(cmd.Context(), source, false)
+		if err != nil {
+			return err
+		}
+		p, pathToManifest, revision = main.Provider, main.PathToManifest, main.Revision
+
+		if cfg.WorkingDirectory, err = workingDirectoryArg(args, 1); err != nil {
+			return err
+		}
+	}
+
+	with := make([]projector.Template, 0, len(templates))
+	for _, name := range templates {
+		t, err := loadTemplate(cmd.Context(), name, false)
+		if err != nil {
+			return err
+		}
+
+		with = append(with, t)
+	}
+
+	if archivePath != "" {
+		// project is generated on disk so that shell scripts can be executed, then archived
+		tmp, err := os.MkdirTemp("", "projector-archive-")
+		if err != nil {
+			return fmt.Errorf("create temporary directory: %w", err)
+		}
+
+		cfg.WorkingDirectory = filepath.Join(tmp, "project")
+		defer func() {
+			// path of partially generated project is reported by failed generation
+			if cfg.KeepOnFailure && !archived {
+				return
+			}
+			_ = os.RemoveAll(tmp)
+		}()
 	}
 
 	verbose.Printf("working directory = %q", cfg.WorkingDirectory)
 
-	return projector.Create(
+	passedVars, err := parseVars(rawVars)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range passedVars {
+		vars[name] = value
+	}
+
+	var asker projector.Asker
+	// questions would be mixed with machine-readable output
+	if !noInput && outputFormat == outputText && prompt.IsTerminal(os.Stdin) {
+		verbose.Println("stdin is a terminal, missing values will be requested interactively")
+		asker = prompt.New(os.Stdin, os.Stdout)
+
+		if !cmd.Flags().Changed("name") {
+			cfg.ProjectName = ""
+		}
+	}
+
+	result, err := projector.Create(
+		cmd.Context(),
 		projector.CreateConfig{
 			Config:          &cfg,
 			Provider:        p,
 			PathToManifest:  pathToManifest,
+			Source:          source,
+			Revision:        revision,
 			IncludeAllSteps: includeAllSteps,
+			Vars:            vars,
+			Asker:           asker,
+			With:            with,
+			Resolver:        openTemplate,
 		},
 	)
+	if err != nil {
+		return err
+	}
+
+	if archivePath != "" {
+		if err := writeArchive(archivePath, cfg.WorkingDirectory); err != nil {
+			if cfg.KeepOnFailure {
+				return fmt.Errorf("%w (generated project is kept in %q)", err, cfg.WorkingDirectory)
+			}
+			return err
+		}
+		archived = true
+	}
+
+	if outputFormat != outputText {
+		document := newCreateDocument(cfg.WorkingDirectory, cfg.DryRun, result)
+		if archivePath != "" {
+			document.WorkingDirectory, document.Archive = "", archivePath
+		}
+
+		return printDocument(outputFormat, document)
+	}
+
+	if cfg.DryRun {
+		printPlan(cfg.WorkingDirectory, result)
+		return nil
+	}
+
+	if len(with) > 0 {
+		printFileOrigins(result.Files)
+	}
+
+	return nil
+}
+
+// workingDirectoryArg returns working directory passed as i-th argument. Working directory
+// must be omitted when project is written into archive.
+func workingDirectoryArg(args []string, i int) (string, error) {
+	switch {
+	case archivePath != "" && len(args) > i:
+		return "", fmt.Errorf("working directory can't be passed with --archive")
+	case archivePath != "":
+		return "", nil
+	case len(args) <= i:
+		return "", fmt.Errorf("working directory is required")
+	default:
+		return args[i], nil
+	}
+}
+
+// applyAnswers copies project info and optional steps from answers into config
+// unless they are passed with flags explicitly.
+func applyAnswers(cmd *cobra.Command, a *projector.Answers) {
+	for _, field := range []struct {
+		flag  string
+		dst   *string
+		value string
+	}{
+		{"name", &cfg.ProjectName, a.ProjectName},
+		{"package", &cfg.ProjectPackage, a.ProjectPackage},
+		{"author", &cfg.ProjectAuthor, a.ProjectAuthor},
+	} {
+		if !cmd.Flags().Changed(field.flag) {
+			*field.dst = field.value
+		}
+	}
+
+	if !cmd.Flags().Changed("include") && !cmd.Flags().Changed("all") {
+		cfg.OptionalSteps = a.OptionalSteps
+	}
+}
+
+// splitTemplates splits names of templates joined with "+". Plus sign of git sources
+// (git+https://...) is not treated as separator.
+func splitTemplates(s string) []string {
+	var (
+		names []string
+		parts = strings.Split(s, "+")
+	)
+
+	for i := 0; i < len(parts); i++ {
+		if parts[i] == "git" && i+1 < len(parts) {
+			names = append(names, "git+"+parts[i+1])
+			i++
+			continue
+		}
+
+		names = append(names, parts[i])
+	}
+
+	return names
+}
+
+func printFileOrigins(files []projector.GeneratedFile) {
+	for _, f := range files {
+		fmt.Printf("%s\t(%s)\n", f.Path, f.Template)
+	}
+}
+
+// printPlan prints tree of files and list of commands that would be produced by generation.
+func printPlan(workingDirectory string, result *projector.Result) {
+	root := &planNode{children: map[string]*planNode{}}
+	for _, f := range result.Files {
+		root.add(strings.Split(filepath.ToSlash(f.Path), "/"), f)
+	}
+
+	fmt.Printf("%s/\n", strings.TrimSuffix(filepath.ToSlash(workingDirectory), "/"))
+	root.print("")
+
+	if len(result.Commands) == 0 {
+		return
+	}
+
+	fmt.Println("\nCommands:")
+	for _, c := range result.Commands {
+		fmt.Printf("  $ %s\n", strings.ReplaceAll(strings.TrimSpace(c), "\n", "\n    "))
+	}
+}
+
+type planNode struct {
+	file     projector.GeneratedFile
+	children map[string]*planNode
+}
+
+func (n *planNode) add(parts []string, f projector.GeneratedFile) {
+	child, ok := n.children[parts[0]]
+	if !ok {
+		child = &planNode{children: map[string]*planNode{}}
+		n.children[parts[0]] = child
+	}
+
+	if len(parts) == 1 {
+		child.file = f
+		return
+	}
+
+	child.add(parts[1:], f)
+}
+
+func (n *planNode) print(indent string) {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		var (
+			child              = n.children[name]
+			branch, nextIndent = "├── ", indent + "│   "
+		)
+
+		if i == len(names)-1 {
+			branch, nextIndent = "└── ", indent+"    "
+		}
+
+		if len(child.children) > 0 {
+			fmt.Printf("%s%s%s/\n", indent, branch, name)
+			child.print(nextIndent)
+			continue
+		}
+
+		fmt.Printf("%s%s%s (%d B, %s)\n", indent, branch, name, child.file.Size, child.file.Mode)
+	}
+}
+
+func parseVars(raw []string) (map[string]string, error) {
+	vars := make(map[string]string, len(raw))
+	for _, kv := range raw {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected key=value", kv)
+		}
+
+		vars[parts[0]] = parts[1]
+	}
+
+	verbose.Printf("variables passed: %v", vars)
+	return vars, nil
 }
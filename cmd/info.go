@@ -16,17 +16,29 @@ var infoCmd = &cobra.Command{
 	RunE:  runInfo,
 }
 
-func runInfo(_ *cobra.Command, args []string) error {
-	var (
-		templateName = args[0]
-		p            = manifest.NewEmbedFSProvider(&resources, embedRoot)
-	)
+func init() {
+	addOutputFlag(infoCmd)
+}
+
+func runInfo(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+
+	p, pathToManifest, err := openTemplate(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
 
-	m, err := manifest.Load(p, filepath.Join(templateName, "projector.toml"))
+	m, err := manifest.Load(cmd.Context(), p, filepath.Join(pathToManifest, "projector.toml"), openTemplate)
 	if err != nil {
 		return err
 	}
 
+	if outputFormat != outputText {
+		return printDocument(outputFormat, newManifestDocument(m))
+	}
+
 	printManifest(m)
 
 	return nil
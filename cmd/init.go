@@ -16,7 +16,7 @@ var initCmd = &cobra.Command{
 	RunE:  runInit,
 }
 
-func runInit(*cobra.Command, []string) error {
+func runInit(cmd *cobra.Command, _ []string) error {
 	wd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("os.Getwd: %w", err)
@@ -33,11 +33,15 @@ func runInit(*cobra.Command, []string) error {
 		WorkingDirectory: wd,
 	}
 
-	return projector.Create(
+	_, err = projector.Create(
+		cmd.Context(),
 		projector.CreateConfig{
 			Config:         cfg,
 			Provider:       p,
 			PathToManifest: "projector",
+			SkipAnswers:    true,
 		},
 	)
+
+	return err
 }
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	projector "github.com/tomakado/projector/pkg"
+)
+
+var (
+	lintCmd = &cobra.Command{
+		Use:   "lint [TEMPLATE]",
+		Short: "Check template for common mistakes and violations of best practices",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runLint,
+	}
+	lintDisabledRules []string
+	lintOutputFormats = []string{outputText, outputSARIF}
+)
+
+func init() {
+	var rules strings.Builder
+	for _, rule := range projector.LintRules() {
+		fmt.Fprintf(&rules, "\n  %-20s %-8s %s", rule.ID, rule.Severity, rule.Description)
+	}
+
+	lintCmd.Long = `Validate template manifest and check template against lint rules. Template in current directory
+is checked if TEMPLATE is not passed. Command fails if issues of error severity are found.
+
+Rules:` + rules.String()
+
+	lintCmd.Flags().StringSliceVar(&lintDisabledRules, "disable", []string{}, "IDs of rules to skip (comma-separated, repeatable)")
+	lintCmd.Flags().StringVar(&outputFormat, "output", outputText, "output format: "+strings.Join(lintOutputFormats, ", "))
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	if outputFormat != outputText && outputFormat != outputSARIF {
+		return fmt.Errorf(
+			"unknown output format %q, expected one of: %s", outputFormat, strings.Join(lintOutputFormats, ", "),
+		)
+	}
+
+	name := "./"
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	p, pathToManifest, err := openTemplate(cmd.Context(), name)
+	if err != nil {
+		return err
+	}
+
+	issues, err := projector.Lint(cmd.Context(), projector.LintConfig{
+		Provider:       p,
+		PathToManifest: pathToManifest,
+		Disabled:       lintDisabledRules,
+		Resolver:       openTemplate,
+	})
+	if err != nil {
+		return printValidationErrors(err)
+	}
+
+	if outputFormat == outputSARIF {
+		if err := printDocument(outputJSON, newSARIFDocument(issues)); err != nil {
+			return err
+		}
+	} else {
+		printLintIssues(issues)
+	}
+
+	var errorsCount int
+	for _, issue := range issues {
+		if issue.Severity == projector.SeverityError {
+			errorsCount++
+		}
+	}
+
+	if errorsCount > 0 {
+		return fmt.Errorf("%d of %d issues are errors", errorsCount, len(issues))
+	}
+
+	return nil
+}
+
+func printLintIssues(issues []projector.LintIssue) {
+	if len(issues) == 0 {
+		fmt.Println("No issues found ✅")
+		return
+	}
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tomakado/projector/internal/pkg/verbose"
@@ -14,7 +15,15 @@ var listCmd = &cobra.Command{
 	RunE:  runList,
 }
 
+func init() {
+	addOutputFlag(listCmd)
+}
+
 func runList(_ *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+
 	verbose.Println("traversing templates tree")
 
 	manifests, err := projector.CollectEmbeddedManifests(&resources, embedRoot, ".")
@@ -22,9 +31,42 @@ func runList(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("collect manifests: %w", err)
 	}
 
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	cached, err := c.List()
+	if err != nil {
+		return fmt.Errorf("collect cached manifests: %w", err)
+	}
+
+	if outputFormat != outputText {
+		templates := make([]templateDocument, 0, len(manifests)+len(cached))
+		for _, m := range manifests {
+			templates = append(templates, templateDocument{Name: m, Kind: "builtin"})
+		}
+
+		for _, e := range cached {
+			templates = append(templates, templateDocument{
+				Name:      e.Source,
+				Kind:      "cached",
+				Version:   e.Version,
+				Revision:  e.Revision,
+				FetchedAt: e.FetchedAt.Format(time.RFC3339),
+			})
+		}
+
+		return printDocument(outputFormat, templates)
+	}
+
 	for _, m := range manifests {
 		fmt.Println(m)
 	}
 
+	for _, e := range cached {
+		fmt.Println(e.Source)
+	}
+
 	return nil
 }
@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tomakado/projector/internal/build"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of command output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+
+	// outputSARIF is format of lint command only.
+	outputSARIF = "sarif"
+)
+
+var (
+	outputFormats = []string{outputText, outputJSON, outputYAML}
+
+	// outputFormat is value of --output flag of command being executed.
+	outputFormat string
+)
+
+// addOutputFlag adds --output flag choosing format of command output.
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputFormat, "output", outputText, "output format: "+strings.Join(outputFormats, ", "))
+}
+
+func validateOutputFormat(format string) error {
+	for _, f := range outputFormats {
+		if f == format {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown output format %q, expected one of: %s", format, strings.Join(outputFormats, ", "))
+}
+
+// printDocument writes document to stdout in passed machine-readable format.
+func printDocument(format string, document interface{}) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(document); err != nil {
+			return fmt.Errorf("encode output: %w", err)
+		}
+	case outputYAML:
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(document); err != nil {
+			return fmt.Errorf("encode output: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return fmt.Errorf("encode output: %w", err)
+		}
+	default:
+		return validateOutputFormat(format)
+	}
+
+	return nil
+}
+
+type templateDocument struct {
+	Name      string `json:"name" yaml:"name"`
+	Kind      string `json:"kind" yaml:"kind"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	Revision  string `json:"revision,omitempty" yaml:"revision,omitempty"`
+	FetchedAt string `json:"fetched_at,omitempty" yaml:"fetched_at,omitempty"`
+}
+
+type manifestDocument struct {
+	Name        string             `json:"name" yaml:"name"`
+	Author      string             `json:"author" yaml:"author"`
+	URL         string             `json:"url,omitempty" yaml:"url,omitempty"`
+	Version     string             `json:"version" yaml:"version"`
+	Description string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Extends     string             `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Variables   []variableDocument `json:"variables" yaml:"variables"`
+	Steps       []stepDocument     `json:"steps" yaml:"steps"`
+}
+
+type variableDocument struct {
+	Name        string      `json:"name" yaml:"name"`
+	Type        string      `json:"type" yaml:"type"`
+	Default     interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Options     []string    `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+type stepDocument struct {
+	Name     string         `json:"name" yaml:"name"`
+	Optional bool           `json:"optional" yaml:"optional"`
+	When     string         `json:"when,omitempty" yaml:"when,omitempty"`
+	Shell    string         `json:"shell,omitempty" yaml:"shell,omitempty"`
+	Files    []fileDocument `json:"files,omitempty" yaml:"files,omitempty"`
+}
+
+type fileDocument struct {
+	Path      string `json:"path" yaml:"path"`
+	Output    string `json:"output" yaml:"output"`
+	When      string `json:"when,omitempty" yaml:"when,omitempty"`
+	Overwrite string `json:"overwrite,omitempty" yaml:"overwrite,omitempty"`
+	Raw       bool   `json:"raw,omitempty" yaml:"raw,omitempty"`
+	Mode      string `json:"mode,omitempty" yaml:"mode,omitempty"`
+}
+
+type validationDocument struct {
+	Manifest string          `json:"manifest" yaml:"manifest"`
+	Valid    bool            `json:"valid" yaml:"valid"`
+	Errors   []errorDocument `json:"errors" yaml:"errors"`
+}
+
+type errorDocument struct {
+	Filename string `json:"filename,omitempty" yaml:"filename,omitempty"`
+	Line     int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Column   int    `json:"column,omitempty" yaml:"column,omitempty"`
+
+	// Field is path to invalid field in TOML notation, e.g. "steps[0].files[1].output". It is empty
+	// for errors not related to particular field, e.g. TOML syntax errors.
+	Field string `json:"field,omitempty" yaml:"field,omitempty"`
+
+	// Step and File are indexes of step and file starting at 0, they are omitted if error is not related to them.
+	Step *int `json:"step,omitempty" yaml:"step,omitempty"`
+	File *int `json:"file,omitempty" yaml:"file,omitempty"`
+
+	Message    string `json:"message" yaml:"message"`
+	Suggestion string `json:"suggestion,omitempty" yaml:"suggestion,omitempty"`
+}
+
+type createDocument struct {
+	WorkingDirectory string       `json:"working_directory,omitempty" yaml:"working_directory,omitempty"`
+	Archive          string       `json:"archive,omitempty" yaml:"archive,omitempty"`
+	DryRun           bool         `json:"dry_run" yaml:"dry_run"`
+	Files            []fileResult `json:"files" yaml:"files"`
+	Steps            []stepResult `json:"steps" yaml:"steps"`
+	Commands         []string     `json:"commands" yaml:"commands"`
+}
+
+type fileResult struct {
+	Path     string `json:"path" yaml:"path"`
+	Template string `json:"template" yaml:"template"`
+	Size     int    `json:"size" yaml:"size"`
+	Mode     string `json:"mode" yaml:"mode"`
+}
+
+type stepResult struct {
+	Name     string `json:"name" yaml:"name"`
+	Template string `json:"template" yaml:"template"`
+}
+
+// sarifDocument is SARIF 2.1.0 log of lint issues, see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type sarifDocument struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// errorDocuments converts manifest loading error into list of documents, one per validation error.
+// Other errors, e.g. missing manifest file, are returned as single document.
+func errorDocuments(err error) []errorDocument {
+	verrs := manifest.ValidationErrors(err)
+	if len(verrs) == 0 {
+		return []errorDocument{{Message: err.Error()}}
+	}
+
+	documents := make([]errorDocument, 0, len(verrs))
+	for _, e := range verrs {
+		document := errorDocument{
+			Filename:   e.Position.Filename,
+			Line:       e.Position.Line,
+			Column:     e.Position.Column,
+			Field:      e.Field,
+			Message:    e.Message,
+			Suggestion: e.Suggestion,
+		}
+
+		if e.Step >= 0 {
+			step := e.Step
+			document.Step = &step
+		}
+
+		if e.File >= 0 {
+			file := e.File
+			document.File = &file
+		}
+
+		documents = append(documents, document)
+	}
+
+	return documents
+}
+
+func newSARIFDocument(issues []projector.LintIssue) sarifDocument {
+	driver := sarifDriver{
+		Name:           "projector",
+		Version:        build.Version(),
+		InformationURI: "https://github.com/tomakado/projector",
+		Rules:          []sarifRule{},
+	}
+
+	// levels of SARIF are the same as severities of lint issues
+	for _, rule := range projector.LintRules() {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.Severity},
+		})
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	for _, issue := range issues {
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(issue.Position.Filename)},
+		}
+
+		if issue.Position.Line > 0 {
+			location.Region = &sarifRegion{StartLine: issue.Position.Line, StartColumn: issue.Position.Column}
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    issue.Rule,
+			Level:     issue.Severity,
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	return sarifDocument{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+}
+
+func newManifestDocument(m *manifest.Manifest) manifestDocument {
+	document := manifestDocument{
+		Name:        m.Name,
+		Author:      m.Author,
+		URL:         m.URL,
+		Version:     m.Version,
+		Description: m.Description,
+		Extends:     m.Extends,
+		Variables:   []variableDocument{},
+		Steps:       []stepDocument{},
+	}
+
+	for _, v := range m.Variables {
+		document.Variables = append(document.Variables, variableDocument{
+			Name:        v.Name,
+			Type:        v.Kind(),
+			Default:     v.Default,
+			Description: v.Description,
+			Options:     v.Options,
+		})
+	}
+
+	for _, s := range m.Steps {
+		step := stepDocument{Name: s.Name, Optional: s.IsOptional, When: s.When, Shell: s.Shell}
+		for _, f := range s.Files {
+			step.Files = append(step.Files, fileDocument{
+				Path:      f.Path,
+				Output:    f.Output,
+				When:      f.When,
+				Overwrite: f.Overwrite,
+				Raw:       f.Raw,
+				Mode:      f.Mode,
+			})
+		}
+
+		document.Steps = append(document.Steps, step)
+	}
+
+	return document
+}
+
+func newCreateDocument(workingDirectory string, dryRun bool, result *projector.Result) createDocument {
+	document := createDocument{
+		WorkingDirectory: workingDirectory,
+		DryRun:           dryRun,
+		Files:            []fileResult{},
+		Steps:            []stepResult{},
+		Commands:         []string{},
+	}
+
+	for _, f := range result.Files {
+		document.Files = append(document.Files, fileResult{
+			Path:     filepath.ToSlash(f.Path),
+			Template: f.Template,
+			Size:     f.Size,
+			Mode:     fmt.Sprintf("%04o", f.Mode.Perm()),
+		})
+	}
+
+	for _, s := range result.Steps {
+		document.Steps = append(document.Steps, stepResult{Name: s.Name, Template: s.Template})
+	}
+
+	document.Commands = append(document.Commands, result.Commands...)
+
+	return document
+}
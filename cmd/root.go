@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/tomakado/projector/internal/pkg/verbose"
@@ -30,7 +34,7 @@ from GitHub.`,
 var resources embed.FS
 
 type provider interface {
-	Get(filename string) ([]byte, error)
+	Get(ctx context.Context, filename string) ([]byte, error)
 }
 
 func init() {
@@ -38,15 +42,37 @@ func init() {
 
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(lintCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(infoCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(updateCmd)
 }
 
-// Execute runs passed command and handles errors.
+// exitInterrupted is exit code of command interrupted by signal, the same as shells use for SIGINT.
+const exitInterrupted = 130
+
+// Execute runs passed command and handles errors. Command is cancelled on SIGINT or SIGTERM,
+// so running shell scripts are killed and working directory is left untouched.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		// second signal terminates projector immediately, e.g. if it waits for answer to prompt
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, context.Canceled):
+		os.Exit(exitInterrupted)
+	default:
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomakado/projector/internal/pkg/verbose"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+// openTemplate resolves template name passed by user into provider and path to manifest inside it.
+// Templates from git repositories are fetched into cache unless they are already cached.
+// Absolute paths and paths starting with "./" or "../" are treated as local template directories.
+// It is manifest.Resolver, so templates extended by manifests are located the same way.
+func openTemplate(ctx context.Context, name string) (manifest.Provider, string, error) {
+	t, err := loadTemplate(ctx, name, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return t.Provider, t.PathToManifest, nil
+}
+
+// loadTemplate resolves template name like openTemplate does and records its source and git revision.
+// Git templates are fetched again even if they are cached if refresh is true.
+func loadTemplate(ctx context.Context, name string, refresh bool) (projector.Template, error) {
+	if isLocalTemplate(name) {
+		verbose.Printf("using manifest from local directory %q", name)
+
+		// absolute path is recorded in answers file, so template can be found from any directory
+		dir, err := filepath.Abs(name)
+		if err != nil {
+			return projector.Template{}, fmt.Errorf("resolve template directory %q: %w", name, err)
+		}
+
+		return projector.Template{Provider: manifest.NewRealFSProvider(dir), PathToManifest: ".", Source: dir}, nil
+	}
+
+	if manifest.IsGitSource(name) {
+		verbose.Printf("using manifest from git repository %q", name)
+
+		c, err := openCache()
+		if err != nil {
+			return projector.Template{}, err
+		}
+
+		fetch := c.Add
+		if refresh {
+			fetch = c.Update
+		}
+
+		e, err := fetch(ctx, name)
+		if err != nil {
+			return projector.Template{}, fmt.Errorf("fetch template: %w", err)
+		}
+
+		verbose.Printf("using cached revision %s fetched at %s", e.Revision, e.FetchedAt)
+		return projector.Template{
+			Provider:       e.Provider(),
+			PathToManifest: ".",
+			Source:         name,
+			Revision:       e.Revision,
+		}, nil
+	}
+
+	verbose.Printf("using manifest name %q in embed fs", name)
+	return projector.Template{
+		Provider:       manifest.NewEmbedFSProvider(&resources, embedRoot),
+		PathToManifest: name,
+		Source:         name,
+	}, nil
+}
+
+// templateSource returns value identifying template in answers file. Local paths are made absolute,
+// so answers can be replayed from any directory.
+func templateSource(name string) string {
+	if !isLocalTemplate(name) {
+		return name
+	}
+
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+
+	return name
+}
+
+func isLocalTemplate(name string) bool {
+	return filepath.IsAbs(name) || strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../")
+}
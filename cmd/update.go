@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tomakado/projector/internal/pkg/verbose"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+var (
+	updateCmd = &cobra.Command{
+		Use:   "update [DIRECTORY]",
+		Short: "Update project to newer version of its template",
+		Long: `Update project generated by projector to newer version of its template.
+
+Template source, version and inputs are read from ` + projector.AnswersFilename + ` file written by create.
+Both the recorded and the new version of template are rendered with these inputs, and changes between
+them are merged into project. Files edited both locally and by template get conflict markers.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runUpdate,
+	}
+	updateFrom string
+	updateTo   string
+	updateVars []string
+)
+
+func init() {
+	updateCmd.Flags().StringVar(&updateFrom, "from", "", "template project was generated from (default recorded in answers)")
+	updateCmd.Flags().StringVar(&updateTo, "to", "", "template to update project to (default latest revision of recorded one)")
+	updateCmd.Flags().StringArrayVar(&updateVars, "var", []string{}, "template variable in key=value format (repeatable)")
+}
+
+func runUpdate(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	a, err := projector.LoadAnswers(filepath.Join(dir, projector.AnswersFilename))
+	if err != nil {
+		return err
+	}
+
+	var (
+		from = make([]projector.Template, 0, len(a.Templates))
+		to   = make([]projector.Template, 0, len(a.Templates))
+	)
+
+	for i, t := range a.Templates {
+		fromName, toName := recordedSource(t), t.Source
+		if i == 0 && updateFrom != "" {
+			fromName = updateFrom
+		}
+		if i == 0 && updateTo != "" {
+			toName = updateTo
+		}
+
+		verbose.Printf("updating template %q from %q to %q", t.Source, fromName, toName)
+
+		fromTemplate, err := loadTemplate(cmd.Context(), fromName, false)
+		if err != nil {
+			return err
+		}
+
+		toTemplate, err := loadTemplate(cmd.Context(), toName, true)
+		if err != nil {
+			return err
+		}
+
+		from = append(from, fromTemplate)
+		to = append(to, toTemplate)
+	}
+
+	vars, err := parseVars(updateVars)
+	if err != nil {
+		return err
+	}
+
+	result, err := projector.Update(cmd.Context(), projector.UpdateConfig{
+		WorkingDirectory: dir,
+		Answers:          a,
+		From:             from,
+		To:               to,
+		Vars:             vars,
+		Resolver:         openTemplate,
+	})
+	if err != nil {
+		return err
+	}
+
+	printUpdate(result)
+
+	if len(result.Conflicted) > 0 {
+		return fmt.Errorf("%d files have conflicts, resolve them and remove conflict markers", len(result.Conflicted))
+	}
+
+	return nil
+}
+
+// recordedSource returns source of exactly the same template revision project was generated from.
+func recordedSource(t projector.AnsweredTemplate) string {
+	if !manifest.IsGitSource(t.Source) || t.Revision == "" {
+		return t.Source
+	}
+
+	s, err := manifest.ParseGitSource(t.Source)
+	if err != nil {
+		return t.Source
+	}
+
+	s.Ref = t.Revision
+	return s.String()
+}
+
+func printUpdate(result *projector.UpdateResult) {
+	for _, group := range []struct {
+		status string
+		paths  []string
+	}{
+		{"added", result.Added},
+		{"updated", result.Updated},
+		{"conflict", result.Conflicted},
+		{"removed", result.Removed},
+		{"skipped", result.Skipped},
+	} {
+		for _, p := range group.paths {
+			fmt.Printf("%-9s %s\n", group.status, p)
+		}
+	}
+
+	if len(result.Commands) == 0 {
+		return
+	}
+
+	fmt.Println("\nCommands added by new version were not executed, run them manually if needed:")
+	for _, c := range result.Commands {
+		fmt.Printf("  $ %s\n", strings.ReplaceAll(strings.TrimSpace(c), "\n", "\n    "))
+	}
+}
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/tomakado/projector/internal/pkg/verbose"
+	projector "github.com/tomakado/projector/pkg"
 	"github.com/tomakado/projector/pkg/manifest"
 )
 
@@ -21,9 +24,14 @@ var (
 
 func init() {
 	validateCmd.Flags().StringVarP(&manifestNameToValidate, "manifest", "m", "", "manifest name or path to validate")
+	addOutputFlag(validateCmd)
 }
 
-func runValidate(_ *cobra.Command, args []string) error {
+func runValidate(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+
 	var p provider
 	if manifestNameToValidate == "" {
 		verbose.Println("manifest name is not passed from flag, trying to read from args")
@@ -32,20 +40,59 @@ func runValidate(_ *cobra.Command, args []string) error {
 			return fmt.Errorf("builtin manifest name as argument is required if path to manifest is not specified")
 		}
 
-		manifestNameToValidate = filepath.Join(args[0], "projector.toml")
-		verbose.Printf("using manifest name %q", manifestNameToValidate)
+		var (
+			pathToManifest string
+			err            error
+		)
+
+		if p, pathToManifest, err = openTemplate(cmd.Context(), args[0]); err != nil {
+			return err
+		}
 
-		p = manifest.NewEmbedFSProvider(&resources, embedRoot)
+		manifestNameToValidate = filepath.Join(pathToManifest, "projector.toml")
+		verbose.Printf("using manifest name %q", manifestNameToValidate)
 	} else {
 		p = manifest.NewRealFSProvider(filepath.Dir(manifestNameToValidate))
 	}
 
-	_, err := manifest.Load(p, manifestNameToValidate)
+	_, err := projector.ValidateTemplate(cmd.Context(), p, manifestNameToValidate, openTemplate)
+
+	if outputFormat != outputText {
+		document := validationDocument{Manifest: manifestNameToValidate, Valid: err == nil, Errors: []errorDocument{}}
+		if err != nil {
+			document.Errors = errorDocuments(err)
+		}
+
+		if printErr := printDocument(outputFormat, document); printErr != nil {
+			return printErr
+		}
+
+		if err != nil {
+			return errors.New("manifest is invalid")
+		}
+		return nil
+	}
+
 	if err != nil {
-		return fmt.Errorf("load manifest: %w", err)
+		return printValidationErrors(fmt.Errorf("load manifest: %w", err))
 	}
 
 	fmt.Println("Manifest is valid ✅")
 
 	return nil
 }
+
+// printValidationErrors prints manifest validation errors contained in err to stderr one per line.
+// Errors of other kinds are returned as is.
+func printValidationErrors(err error) error {
+	verrs := manifest.ValidationErrors(err)
+	if len(verrs) == 0 {
+		return err
+	}
+
+	for _, e := range verrs {
+		fmt.Fprintln(os.Stderr, e)
+	}
+
+	return errors.New("manifest is invalid")
+}
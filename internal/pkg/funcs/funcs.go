@@ -0,0 +1,333 @@
+// Package funcs provides functions available in templates of files, output paths,
+// shell scripts and conditions.
+package funcs
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Map returns functions available in templates. New map is returned on each call, so callers may extend it.
+func Map() template.FuncMap {
+	return template.FuncMap{
+		// case conversion
+		"lower":  strings.ToLower,
+		"upper":  strings.ToUpper,
+		"title":  Title,
+		"snake":  Snake,
+		"camel":  Camel,
+		"pascal": Pascal,
+		"kebab":  Kebab,
+
+		// pluralisation
+		"plural":   Plural,
+		"singular": Singular,
+
+		// strings
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+
+		// date and time
+		"now":  time.Now,
+		"date": func(layout string, t time.Time) string { return t.Format(layout) },
+
+		// misc
+		"uuid":     UUID,
+		"env":      os.Getenv,
+		"default":  Default,
+		"required": Required,
+
+		// encoding
+		"toJson": ToJSON,
+		"toYaml": ToYAML,
+		"toToml": ToTOML,
+
+		// paths
+		"pathBase":  path.Base,
+		"pathDir":   path.Dir,
+		"pathExt":   path.Ext,
+		"pathClean": path.Clean,
+		"pathJoin":  path.Join,
+	}
+}
+
+// Words splits s into lowercase words on non-alphanumeric characters and case changes,
+// e.g. "myHTTPServer" and "my-http_server" both give [my http server].
+func Words(s string) []string {
+	var (
+		words   []string
+		current []rune
+		runes   = []rune(s)
+	)
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	for i, r := range runes {
+		if !isAlnum(r) {
+			flush()
+			continue
+		}
+
+		if isUpper(r) && len(current) > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && isLower(runes[i+1])
+
+			// split "myServer" before "S" and "HTTPServer" before "S"
+			if !isUpper(prev) || nextIsLower {
+				flush()
+			}
+		}
+
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+// Snake converts s to snake_case.
+func Snake(s string) string {
+	return strings.Join(Words(s), "_")
+}
+
+// Kebab converts s to kebab-case.
+func Kebab(s string) string {
+	return strings.Join(Words(s), "-")
+}
+
+// Camel converts s to camelCase.
+func Camel(s string) string {
+	words := Words(s)
+	for i := 1; i < len(words); i++ {
+		words[i] = Title(words[i])
+	}
+
+	return strings.Join(words, "")
+}
+
+// Pascal converts s to PascalCase.
+func Pascal(s string) string {
+	words := Words(s)
+	for i := range words {
+		words[i] = Title(words[i])
+	}
+
+	return strings.Join(words, "")
+}
+
+// Title makes first letter of s upper case.
+func Title(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r := []rune(s)
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
+
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"mouse":  "mice",
+	"goose":  "geese",
+	"tooth":  "teeth",
+	"foot":   "feet",
+	"datum":  "data",
+	"index":  "indices",
+
+	// words which regular singular form can't be guessed from plural one
+	"alias":  "aliases",
+	"quiz":   "quizzes",
+	"cache":  "caches",
+	"niche":  "niches",
+	"use":    "uses",
+	"abuse":  "abuses",
+	"excuse": "excuses",
+	"fuse":   "fuses",
+}
+
+// Plural returns English plural form of noun s using common rules, e.g. "user" -> "users", "category" -> "categories".
+func Plural(s string) string {
+	lower := strings.ToLower(s)
+	for singular, plural := range irregularPlurals {
+		if lower == singular {
+			return matchCase(s, plural)
+		}
+	}
+
+	switch {
+	case hasAnySuffix(lower, "s", "x", "z", "ch", "sh"):
+		return s + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !isVowel(lower[len(lower)-2]):
+		return s[:len(s)-1] + "ies"
+	default:
+		return s + "s"
+	}
+}
+
+// Singular returns English singular form of noun s, it is reverse of Plural.
+func Singular(s string) string {
+	lower := strings.ToLower(s)
+	for singular, plural := range irregularPlurals {
+		if lower == plural {
+			return matchCase(s, singular)
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(lower) > 3:
+		return s[:len(s)-3] + "y"
+	case hasAnySuffix(lower, "sses", "xes", "zzes", "ches", "shes"):
+		// "es" is added to sibilant: "classes", "boxes", "buzzes", "matches", "dishes"
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "uses"):
+		// "houses" and "causes" keep "e", "buses" and "statuses" don't
+		if len(lower) > 4 && isVowel(lower[len(lower)-5]) {
+			return s[:len(s)-1]
+		}
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "s") && !hasAnySuffix(lower, "ss", "us", "is"):
+		return s[:len(s)-1]
+	default:
+		return s
+	}
+}
+
+// UUID returns random UUID version 4.
+func UUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate uuid: %w", err)
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// Default returns value if it is not empty, otherwise def is returned. Intended for pipelines:
+// {{ .Vars.port | default 8080 }}.
+func Default(def, value interface{}) interface{} {
+	if isEmpty(value) {
+		return def
+	}
+
+	return value
+}
+
+// Required returns value or error with passed message if value is empty.
+func Required(msg string, value interface{}) (interface{}, error) {
+	if isEmpty(value) {
+		return nil, errors.New(msg)
+	}
+
+	return value, nil
+}
+
+// ToJSON encodes v as JSON.
+func ToJSON(v interface{}) (string, error) {
+	bts, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encode json: %w", err)
+	}
+
+	return string(bts), nil
+}
+
+// ToYAML encodes v as YAML without trailing newline.
+func ToYAML(v interface{}) (string, error) {
+	bts, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encode yaml: %w", err)
+	}
+
+	return strings.TrimSuffix(string(bts), "\n"), nil
+}
+
+// ToTOML encodes v as TOML without trailing newline. v must be map or struct.
+func ToTOML(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return "", fmt.Errorf("encode toml: %w", err)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+func matchCase(original, s string) string {
+	if original != "" && isUpper([]rune(original)[0]) {
+		return Title(s)
+	}
+
+	return s
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isUpper(r rune) bool {
+	return unicode.IsUpper(r)
+}
+
+func isLower(r rune) bool {
+	return unicode.IsLower(r)
+}
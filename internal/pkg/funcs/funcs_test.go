@@ -0,0 +1,96 @@
+package funcs_test
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/projector/internal/pkg/funcs"
+)
+
+func TestMap(t *testing.T) {
+	type testCase struct {
+		template string
+		expected string
+		isValid  bool
+	}
+
+	data := map[string]interface{}{
+		"Name":  "my awesome-HTTPServer",
+		"Empty": "",
+		"Vars":  map[string]interface{}{"db": "postgres", "ports": []int{80, 443}},
+	}
+
+	testCases := []testCase{
+		{template: `{{ snake .Name }}`, expected: "my_awesome_http_server", isValid: true},
+		{template: `{{ kebab .Name }}`, expected: "my-awesome-http-server", isValid: true},
+		{template: `{{ camel .Name }}`, expected: "myAwesomeHttpServer", isValid: true},
+		{template: `{{ pascal .Name }}`, expected: "MyAwesomeHttpServer", isValid: true},
+		{template: `{{ pascal "user_id" }}`, expected: "UserId", isValid: true},
+		{template: `{{ upper "go" }}{{ lower "GO" }}{{ title "go" }}`, expected: "GOgoGo", isValid: true},
+		{template: `{{ plural "user" }} {{ plural "category" }} {{ plural "box" }} {{ plural "Person" }}`, expected: "users categories boxes People", isValid: true},
+		{template: `{{ singular "users" }} {{ singular "categories" }} {{ singular "boxes" }} {{ singular "status" }}`, expected: "user category box status", isValid: true},
+		{template: `{{ singular "houses" }} {{ singular "cases" }} {{ singular "responses" }} {{ singular "sizes" }}`, expected: "house case response size", isValid: true},
+		{template: `{{ singular "buses" }} {{ singular "statuses" }} {{ singular "classes" }} {{ singular "matches" }}`, expected: "bus status class match", isValid: true},
+		{template: `{{ singular "Caches" }} {{ singular "aliases" }} {{ singular "indexes" }} {{ singular "dishes" }}`, expected: "Cache alias index dish", isValid: true},
+		{template: `{{ snake "ÜberServiceÅrhus" }} {{ kebab "crème brûlée" }} {{ camel "привет мир" }}`, expected: "über_service_århus crème-brûlée приветМир", isValid: true},
+		{template: `{{ pascal "straße_façade" }} {{ snake "日本語テキスト" }}`, expected: "StraßeFaçade 日本語テキスト", isValid: true},
+		{template: `{{ trim "  x " }}|{{ trimPrefix "v" "v1.0" }}|{{ trimSuffix ".tpl" "main.go.tpl" }}`, expected: "x|1.0|main.go", isValid: true},
+		{template: `{{ replace "-" "_" "a-b-c" }}`, expected: "a_b_c", isValid: true},
+		{template: `{{ split "/" "a/b" | join "." }}`, expected: "a.b", isValid: true},
+		{template: `{{ .Empty | default "fallback" }}`, expected: "fallback", isValid: true},
+		{template: `{{ .Vars.db | default "sqlite" }}`, expected: "postgres", isValid: true},
+		{template: `{{ .Vars.missing | default 8080 }}`, expected: "8080", isValid: true},
+		{template: `{{ .Vars.db | required "db is required" }}`, expected: "postgres", isValid: true},
+		{template: `{{ .Empty | required "value is required" }}`, isValid: false},
+		{template: `{{ toJson .Vars.ports }}`, expected: "[80,443]", isValid: true},
+		{template: `{{ toYaml .Vars }}`, expected: "db: postgres\nports:\n    - 80\n    - 443", isValid: true},
+		{template: `{{ toToml .Vars }}`, expected: "db = \"postgres\"\nports = [80, 443]", isValid: true},
+		{template: `{{ pathBase "a/b/c.go" }} {{ pathDir "a/b/c.go" }} {{ pathExt "c.go" }} {{ pathJoin "a" "../b" "c" }}`, expected: "c.go a/b .go b/c", isValid: true},
+		{template: `{{ date "2006" now | len }}`, expected: "4", isValid: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.template, func(t *testing.T) {
+			actual, err := render(tc.template, data)
+
+			if tc.isValid {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, actual)
+				return
+			}
+
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestMap_EnvAndUUID(t *testing.T) {
+	require.NoError(t, os.Setenv("PROJECTOR_FUNCS_TEST", "value"))
+	t.Cleanup(func() { _ = os.Unsetenv("PROJECTOR_FUNCS_TEST") })
+
+	actual, err := render(`{{ env "PROJECTOR_FUNCS_TEST" }}`, nil)
+	require.NoError(t, err)
+	require.Equal(t, "value", actual)
+
+	id, err := render(`{{ uuid }}`, nil)
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+}
+
+func render(text string, data interface{}) (string, error) {
+	t, err := template.New("test").Funcs(funcs.Map()).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,96 @@
+// Package gittest provides utilities for tests working with git repositories.
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// SkipIfNoGit skips test if git is not installed.
+func SkipIfNoGit(t *testing.T) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+}
+
+// NewBareRepo creates bare repository with tpl/hello.txt file in three revisions:
+// tag v1.0.0, default branch "main" and branch "feature". Returned repository is
+// a Repo that can be used to push more commits to default branch.
+func NewBareRepo(t *testing.T) *Repo {
+	t.Helper()
+
+	root := t.TempDir()
+	r := &Repo{
+		t:    t,
+		work: filepath.Join(root, "work"),
+		Bare: filepath.Join(root, "templates.git"),
+	}
+
+	require.NoError(t, os.MkdirAll(r.work, 0o755))
+	r.git(r.work, "init", "--quiet", "--initial-branch=main")
+
+	r.Commit("tpl/hello.txt", "hello from v1\n")
+	r.git(r.work, "tag", "v1.0.0")
+
+	r.git(r.work, "checkout", "--quiet", "-b", "feature")
+	r.Commit("tpl/hello.txt", "hello from feature\n")
+
+	r.git(r.work, "checkout", "--quiet", "main")
+	r.Commit("tpl/hello.txt", "hello from v2\n")
+
+	r.git(root, "clone", "--quiet", "--bare", r.work, r.Bare)
+	r.git(r.work, "remote", "add", "origin", r.Bare)
+
+	return r
+}
+
+// Repo is bare repository with working copy used to prepare commits.
+type Repo struct {
+	t    *testing.T
+	work string
+
+	// Bare is path to bare repository.
+	Bare string
+}
+
+// Commit writes file to working copy and commits it to current branch.
+func (r *Repo) Commit(filename, content string) {
+	r.t.Helper()
+
+	path := filepath.Join(r.work, filepath.FromSlash(filename))
+	require.NoError(r.t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(r.t, os.WriteFile(path, []byte(content), 0o644))
+	r.git(r.work, "add", "-A")
+	r.git(r.work, "commit", "--quiet", "-m", "update "+filename)
+}
+
+// Push pushes default branch of working copy to bare repository.
+func (r *Repo) Push() {
+	r.t.Helper()
+	r.git(r.work, "push", "--quiet", "origin", "main")
+}
+
+// Branch creates branch from default branch with single commit writing file and pushes it to bare repository.
+func (r *Repo) Branch(name, filename, content string) {
+	r.t.Helper()
+
+	r.git(r.work, "checkout", "--quiet", "-b", name, "main")
+	r.Commit(filename, content)
+	r.git(r.work, "push", "--quiet", "origin", name)
+	r.git(r.work, "checkout", "--quiet", "main")
+}
+
+func (r *Repo) git(dir string, args ...string) {
+	r.t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@test"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(output))
+}
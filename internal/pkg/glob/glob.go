@@ -0,0 +1,85 @@
+// Package glob implements matching of slash-separated paths against shell patterns
+// extended with "**" segment that matches zero or more directories.
+package glob
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const anyDirs = "**"
+
+// HasMeta reports whether pattern contains any of special characters recognized by Match.
+func HasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// Validate checks pattern syntax.
+func Validate(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == anyDirs {
+			continue
+		}
+
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Match reports whether name matches pattern. Pattern syntax is the same as in path.Match
+// except "**" segment that matches any number of path segments, including none.
+func Match(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// Base returns leading part of pattern without special characters, i.e. directory
+// where matching should start from. "." is returned if pattern has no such part.
+func Base(pattern string) string {
+	var static []string
+	for _, segment := range strings.Split(path.Dir(pattern), "/") {
+		if HasMeta(segment) {
+			break
+		}
+		static = append(static, segment)
+	}
+
+	if len(static) == 0 {
+		return "."
+	}
+
+	return path.Join(static...)
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == anyDirs {
+			if len(pattern) == 1 {
+				return true
+			}
+
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,44 @@
+package glob_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/projector/internal/pkg/glob"
+)
+
+func TestMatch(t *testing.T) {
+	type testCase struct {
+		pattern  string
+		name     string
+		expected bool
+	}
+
+	testCases := []testCase{
+		{pattern: "*.tpl", name: "main.go.tpl", expected: true},
+		{pattern: "*.tpl", name: "cmd/main.go.tpl", expected: false},
+		{pattern: "src/**/*.tpl", name: "src/main.go.tpl", expected: true},
+		{pattern: "src/**/*.tpl", name: "src/a/b/c.tpl", expected: true},
+		{pattern: "src/**/*.tpl", name: "lib/a.tpl", expected: false},
+		{pattern: "**", name: "any/thing", expected: true},
+		{pattern: "**/*_test.go", name: "main_test.go", expected: true},
+		{pattern: "src/**", name: "src", expected: true},
+		{pattern: "src/?.go", name: "src/ab.go", expected: false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, glob.Match(tc.pattern, tc.name), "%q ~ %q", tc.pattern, tc.name)
+	}
+}
+
+func TestBase(t *testing.T) {
+	require.Equal(t, "src", glob.Base("src/**/*.tpl"))
+	require.Equal(t, "src/cmd", glob.Base("src/cmd/*.go"))
+	require.Equal(t, ".", glob.Base("*.go"))
+	require.Equal(t, ".", glob.Base("**/*.go"))
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, glob.Validate("src/**/[a-z]*.go"))
+	require.Error(t, glob.Validate("src/[a-z.go"))
+}
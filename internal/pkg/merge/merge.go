@@ -0,0 +1,190 @@
+// Package merge implements line-based three-way merge of text files.
+package merge
+
+import (
+	"bytes"
+)
+
+// Labels are names of sides printed in conflict markers.
+type Labels struct {
+	Ours   string
+	Theirs string
+}
+
+// Merge applies changes made between base and theirs onto ours. Lines changed differently on both sides
+// are left as conflict between "<<<<<<<", "=======" and ">>>>>>>" markers. Number of conflicts is returned.
+func Merge(base, ours, theirs []byte, labels Labels) ([]byte, int) {
+	var (
+		baseLines   = splitLines(base)
+		oursLines   = splitLines(ours)
+		theirsLines = splitLines(theirs)
+		oursMatch   = match(baseLines, oursLines)
+		theirsMatch = match(baseLines, theirsLines)
+
+		merged    bytes.Buffer
+		conflicts int
+		i, a, b   int
+	)
+
+	for {
+		if i < len(baseLines) && oursMatch[i] == a && theirsMatch[i] == b {
+			merged.WriteString(baseLines[i])
+			i, a, b = i+1, a+1, b+1
+			continue
+		}
+
+		// find next base line kept by both sides, everything before it is changed chunk
+		j := i
+		for j < len(baseLines) && (oursMatch[j] < 0 || theirsMatch[j] < 0) {
+			j++
+		}
+
+		aEnd, bEnd := len(oursLines), len(theirsLines)
+		if j < len(baseLines) {
+			aEnd, bEnd = oursMatch[j], theirsMatch[j]
+		}
+
+		var (
+			baseChunk   = baseLines[i:j]
+			oursChunk   = oursLines[a:aEnd]
+			theirsChunk = theirsLines[b:bEnd]
+		)
+
+		if j == len(baseLines) && len(baseChunk) == 0 && len(oursChunk) == 0 && len(theirsChunk) == 0 {
+			break
+		}
+
+		switch {
+		case equal(oursChunk, baseChunk):
+			writeLines(&merged, theirsChunk)
+		case equal(theirsChunk, baseChunk), equal(oursChunk, theirsChunk):
+			writeLines(&merged, oursChunk)
+		default:
+			conflicts++
+			writeConflict(&merged, oursChunk, theirsChunk, labels)
+		}
+
+		i, a, b = j, aEnd, bEnd
+	}
+
+	return merged.Bytes(), conflicts
+}
+
+func writeConflict(w *bytes.Buffer, ours, theirs []string, labels Labels) {
+	w.WriteString("<<<<<<< " + labels.Ours + "\n")
+	writeLines(w, ours)
+	ensureNewline(w)
+	w.WriteString("=======\n")
+	writeLines(w, theirs)
+	ensureNewline(w)
+	w.WriteString(">>>>>>> " + labels.Theirs + "\n")
+}
+
+func ensureNewline(w *bytes.Buffer) {
+	if w.Len() > 0 && w.Bytes()[w.Len()-1] != '\n' {
+		w.WriteByte('\n')
+	}
+}
+
+func writeLines(w *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		w.WriteString(line)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitLines splits content into lines keeping line endings.
+func splitLines(content []byte) []string {
+	var lines []string
+	for len(content) > 0 {
+		i := bytes.IndexByte(content, '\n')
+		if i < 0 {
+			lines = append(lines, string(content))
+			break
+		}
+
+		lines = append(lines, string(content[:i+1]))
+		content = content[i+1:]
+	}
+
+	return lines
+}
+
+// match returns index of matching line in other for each line of base or -1 if line is not kept.
+// Matching is longest common subsequence of lines.
+func match(base, other []string) []int {
+	matches := make([]int, len(base))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// common prefix and suffix are matched without building LCS table
+	prefix := 0
+	for prefix < len(base) && prefix < len(other) && base[prefix] == other[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(base)-prefix && suffix < len(other)-prefix &&
+		base[len(base)-1-suffix] == other[len(other)-1-suffix] {
+		matches[len(base)-1-suffix] = len(other) - 1 - suffix
+		suffix++
+	}
+
+	var (
+		x = base[prefix : len(base)-suffix]
+		y = other[prefix : len(other)-suffix]
+	)
+
+	if len(x) == 0 || len(y) == 0 {
+		return matches
+	}
+
+	// lcs[i][j] is length of LCS of x[i:] and y[j:]
+	lcs := make([][]int32, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(y)+1)
+	}
+
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < len(x) && j < len(y); {
+		switch {
+		case x[i] == y[j]:
+			matches[prefix+i] = prefix + j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return matches
+}
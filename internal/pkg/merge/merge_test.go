@@ -0,0 +1,95 @@
+package merge_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/projector/internal/pkg/merge"
+)
+
+func TestMerge(t *testing.T) {
+	type testCase struct {
+		name              string
+		base              string
+		ours              string
+		theirs            string
+		expected          string
+		expectedConflicts int
+	}
+
+	testCases := []testCase{
+		{
+			name:     "nothing changed",
+			base:     "a\nb\nc\n",
+			ours:     "a\nb\nc\n",
+			theirs:   "a\nb\nc\n",
+			expected: "a\nb\nc\n",
+		},
+		{
+			name:     "only theirs changed",
+			base:     "a\nb\nc\n",
+			ours:     "a\nb\nc\n",
+			theirs:   "a\nB\nc\nd\n",
+			expected: "a\nB\nc\nd\n",
+		},
+		{
+			name:     "only ours changed",
+			base:     "a\nb\nc\n",
+			ours:     "x\na\nb\n",
+			theirs:   "a\nb\nc\n",
+			expected: "x\na\nb\n",
+		},
+		{
+			name:     "different lines changed on both sides",
+			base:     "a\nb\nc\nd\ne\n",
+			ours:     "A\nb\nc\nd\ne\n",
+			theirs:   "a\nb\nc\nd\nE\nf\n",
+			expected: "A\nb\nc\nd\nE\nf\n",
+		},
+		{
+			name:     "the same change on both sides",
+			base:     "a\nb\nc\n",
+			ours:     "a\nB\nc\n",
+			theirs:   "a\nB\nc\n",
+			expected: "a\nB\nc\n",
+		},
+		{
+			name:              "the same line changed differently",
+			base:              "a\nb\nc\n",
+			ours:              "a\nmine\nc\n",
+			theirs:            "a\nyours\nc\n",
+			expected:          "a\n<<<<<<< local\nmine\n=======\nyours\n>>>>>>> template\nc\n",
+			expectedConflicts: 1,
+		},
+		{
+			name:              "file added on both sides",
+			base:              "",
+			ours:              "mine",
+			theirs:            "yours\n",
+			expected:          "<<<<<<< local\nmine\n=======\nyours\n>>>>>>> template\n",
+			expectedConflicts: 1,
+		},
+		{
+			name:     "insertions at different places",
+			base:     "func a() {}\n\nfunc b() {}\n",
+			ours:     "// a does nothing\nfunc a() {}\n\nfunc b() {}\n",
+			theirs:   "func a() {}\n\nfunc b() {}\n\nfunc c() {}\n",
+			expected: "// a does nothing\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			merged, conflicts := merge.Merge(
+				[]byte(tc.base),
+				[]byte(tc.ours),
+				[]byte(tc.theirs),
+				merge.Labels{Ours: "local", Theirs: "template"},
+			)
+
+			require.Equal(t, tc.expected, string(merged))
+			require.Equal(t, tc.expectedConflicts, conflicts)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Prompt asks user for values in line-oriented manner using passed reader and writer.
+type Prompt struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func New(in io.Reader, out io.Writer) *Prompt {
+	return &Prompt{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+}
+
+// IsTerminal reports whether passed file is attached to terminal.
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// String asks for arbitrary string. Default value is used if user enters empty line.
+// Question is repeated until validate (if not nil) accepts entered value.
+func (p *Prompt) String(label, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", label)
+		}
+
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+
+		if answer == "" {
+			answer = def
+		}
+
+		if validate != nil {
+			if err := validate(answer); err != nil {
+				fmt.Fprintf(p.out, "  %s\n", err)
+				continue
+			}
+		}
+
+		return answer, nil
+	}
+}
+
+// Confirm asks yes/no question.
+func (p *Prompt) Confirm(label string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", label, hint)
+
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+
+		fmt.Fprintln(p.out, "  please answer y or n")
+	}
+}
+
+// Select asks to choose exactly one of options either by number or by value.
+func (p *Prompt) Select(label string, options []string, def string) (string, error) {
+	p.printOptions(label, options)
+
+	var selected string
+	_, err := p.String("Choose", def, func(answer string) error {
+		option, ok := p.resolveOption(options, answer)
+		if !ok {
+			return fmt.Errorf("%q is not one of options", answer)
+		}
+
+		selected = option
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return selected, nil
+}
+
+// MultiSelect asks to choose any number of options separated with commas.
+func (p *Prompt) MultiSelect(label string, options []string) ([]string, error) {
+	p.printOptions(label, options)
+
+	var selected []string
+	_, err := p.String("Choose (comma-separated, empty for none)", "", func(answer string) error {
+		selected = nil
+
+		for _, item := range strings.Split(answer, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			option, ok := p.resolveOption(options, item)
+			if !ok {
+				return fmt.Errorf("%q is not one of options", item)
+			}
+			selected = append(selected, option)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return selected, nil
+}
+
+func (p *Prompt) printOptions(label string, options []string) {
+	fmt.Fprintf(p.out, "%s:\n", label)
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+}
+
+// resolveOption returns option equal to answer or option numbered by answer. Exact match goes first,
+// so numeric options are chosen by their values.
+func (p *Prompt) resolveOption(options []string, answer string) (string, bool) {
+	for _, option := range options {
+		if option == answer {
+			return option, true
+		}
+	}
+
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1], true
+	}
+
+	return "", false
+}
+
+func (p *Prompt) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("read answer: %w", err)
+	}
+
+	return strings.TrimSpace(line), nil
+}
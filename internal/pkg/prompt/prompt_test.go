@@ -0,0 +1,224 @@
+package prompt_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/projector/internal/pkg/prompt"
+)
+
+func TestPrompt_String(t *testing.T) {
+	requireValue := func(s string) error {
+		if s == "" {
+			return errors.New("value is required")
+		}
+		return nil
+	}
+
+	type testCase struct {
+		name        string
+		input       string
+		def         string
+		validate    func(string) error
+		expected    string
+		expectedOut string
+		expectErr   bool
+	}
+
+	testCases := []testCase{
+		{
+			name:        "entered value",
+			input:       "my-app\n",
+			def:         "app",
+			expected:    "my-app",
+			expectedOut: "Name [app]: ",
+		},
+		{
+			name:        "default value on empty line",
+			input:       "\n",
+			def:         "app",
+			expected:    "app",
+			expectedOut: "Name [app]: ",
+		},
+		{
+			name:        "value is trimmed",
+			input:       "  my-app  \n",
+			expected:    "my-app",
+			expectedOut: "Name: ",
+		},
+		{
+			name:        "last line without newline",
+			input:       "my-app",
+			expected:    "my-app",
+			expectedOut: "Name: ",
+		},
+		{
+			name:        "question is repeated until value is valid",
+			input:       "\n\nmy-app\n",
+			validate:    requireValue,
+			expected:    "my-app",
+			expectedOut: "Name:   value is required\nName:   value is required\nName: ",
+		},
+		{
+			name:      "input is over before valid value",
+			input:     "\n",
+			validate:  requireValue,
+			expectErr: true,
+		},
+		{
+			name:      "empty input",
+			input:     "",
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			actual, err := prompt.New(strings.NewReader(tc.input), &out).String("Name", tc.def, tc.validate)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+			require.Equal(t, tc.expectedOut, out.String())
+		})
+	}
+}
+
+func TestPrompt_Confirm(t *testing.T) {
+	type testCase struct {
+		name        string
+		input       string
+		def         bool
+		expected    bool
+		expectedOut string
+		expectErr   bool
+	}
+
+	testCases := []testCase{
+		{name: "yes", input: "y\n", expected: true, expectedOut: "Continue? [y/N]: "},
+		{name: "full yes in upper case", input: "YES\n", expected: true, expectedOut: "Continue? [y/N]: "},
+		{name: "no", input: "n\n", def: true, expected: false, expectedOut: "Continue? [Y/n]: "},
+		{name: "default yes", input: "\n", def: true, expected: true, expectedOut: "Continue? [Y/n]: "},
+		{name: "default no", input: "\n", expected: false, expectedOut: "Continue? [y/N]: "},
+		{
+			name:        "question is repeated on unknown answer",
+			input:       "maybe\nsure\nyes\n",
+			expected:    true,
+			expectedOut: strings.Repeat("Continue? [y/N]:   please answer y or n\n", 2) + "Continue? [y/N]: ",
+		},
+		{name: "input is over before answer", input: "maybe\n", expectErr: true},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			actual, err := prompt.New(strings.NewReader(tc.input), &out).Confirm("Continue?", tc.def)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+			require.Equal(t, tc.expectedOut, out.String())
+		})
+	}
+}
+
+func TestPrompt_Select(t *testing.T) {
+	options := []string{"postgres", "mysql", "sqlite"}
+
+	type testCase struct {
+		name      string
+		options   []string
+		input     string
+		def       string
+		expected  string
+		expectErr bool
+	}
+
+	testCases := []testCase{
+		{name: "by number", input: "2\n", expected: "mysql"},
+		{name: "numeric option by value", options: []string{"3", "1"}, input: "1\n", expected: "1"},
+		{name: "numeric option by number", options: []string{"3", "1"}, input: "2\n", expected: "1"},
+		{name: "by value", input: "sqlite\n", expected: "sqlite"},
+		{name: "default", input: "\n", def: "postgres", expected: "postgres"},
+		{name: "number out of range is asked again", input: "4\n0\n1\n", expected: "postgres"},
+		{name: "unknown value is asked again", input: "oracle\nmysql\n", expected: "mysql"},
+		{name: "empty answer without default is asked again", input: "\n3\n", expected: "sqlite"},
+		{name: "input is over before valid option", input: "oracle\n", expectErr: true},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			opts := options
+			if tc.options != nil {
+				opts = tc.options
+			}
+
+			actual, err := prompt.New(strings.NewReader(tc.input), &out).Select("Database", opts, tc.def)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+			require.True(
+				t,
+				strings.HasPrefix(out.String(), "Database:\n  1) "+opts[0]+"\n  2) "+opts[1]+"\n"),
+				"options are printed: %q",
+				out.String(),
+			)
+		})
+	}
+}
+
+func TestPrompt_MultiSelect(t *testing.T) {
+	options := []string{"docker", "ci", "linter"}
+
+	type testCase struct {
+		name      string
+		input     string
+		expected  []string
+		expectErr bool
+	}
+
+	testCases := []testCase{
+		{name: "numbers and values", input: "1, linter\n", expected: []string{"docker", "linter"}},
+		{name: "none", input: "\n", expected: nil},
+		{name: "empty items are ignored", input: "2,,\n", expected: []string{"ci"}},
+		{name: "unknown option is asked again", input: "1,k8s\n3\n", expected: []string{"linter"}},
+		{name: "input is over before valid options", input: "k8s\n", expectErr: true},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			actual, err := prompt.New(strings.NewReader(tc.input), &out).MultiSelect("Optional steps", options)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
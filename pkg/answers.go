@@ -0,0 +1,120 @@
+package projector
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tomakado/projector/internal/build"
+	"github.com/tomakado/projector/internal/pkg/verbose"
+)
+
+// AnswersFilename is name of file with answers written to root of generated project.
+const AnswersFilename = ".projector-answers.toml"
+
+// Answers records inputs of generation, so project can be generated again non-interactively.
+type Answers struct {
+	ProjectorVersion string             `toml:"projector_version"`
+	ProjectName      string             `toml:"project_name"`
+	ProjectPackage   string             `toml:"project_package"`
+	ProjectAuthor    string             `toml:"project_author"`
+	OptionalSteps    []string           `toml:"optional_steps,omitempty"`
+	Templates        []AnsweredTemplate `toml:"templates"`
+
+	// Vars contains resolved values of template variables.
+	Vars map[string]interface{} `toml:"vars,omitempty"`
+}
+
+// AnsweredTemplate is template applied during generation. The first template in answers is the main one.
+type AnsweredTemplate struct {
+	// Source is name of builtin template, git source or path to template directory.
+	Source  string `toml:"source"`
+	Version string `toml:"version"`
+
+	// Revision is git commit of template fetched from git repository. It is used to render
+	// the same template version again during update.
+	Revision string `toml:"revision,omitempty"`
+}
+
+// LoadAnswers reads answers file.
+func LoadAnswers(path string) (*Answers, error) {
+	verbose.Printf("loading answers from %q", path)
+
+	var a Answers
+	if _, err := toml.DecodeFile(path, &a); err != nil {
+		return nil, fmt.Errorf("load answers %q: %w", path, err)
+	}
+
+	if len(a.Templates) == 0 {
+		return nil, fmt.Errorf("load answers %q: no templates", path)
+	}
+
+	return &a, nil
+}
+
+// Save writes answers to file.
+func (a *Answers) Save(path string) error {
+	return a.save(NewDirOutput(""), path)
+}
+
+// save writes answers to file of output.
+func (a *Answers) save(output Output, path string) error {
+	verbose.Printf("writing answers to %q", path)
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(a); err != nil {
+		return fmt.Errorf("encode answers: %w", err)
+	}
+
+	if err := output.WriteFile(path, buf.Bytes(), defaultFileMode); err != nil {
+		return fmt.Errorf("write answers %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// RawVars returns variables in format accepted by CreateConfig.Vars.
+func (a *Answers) RawVars() map[string]string {
+	vars := make(map[string]string, len(a.Vars))
+	for name, value := range a.Vars {
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, 0, len(list))
+			for _, item := range list {
+				items = append(items, fmt.Sprint(item))
+			}
+			vars[name] = strings.Join(items, ",")
+			continue
+		}
+
+		vars[name] = formatValue(value)
+	}
+
+	return vars
+}
+
+func newAnswers(config *Config, templates []loadedTemplate) *Answers {
+	a := &Answers{
+		ProjectorVersion: build.Version(),
+		ProjectName:      config.ProjectName,
+		ProjectPackage:   config.ProjectPackage,
+		ProjectAuthor:    config.ProjectAuthor,
+		OptionalSteps:    config.OptionalSteps,
+		Vars:             config.Vars,
+	}
+
+	for _, t := range templates {
+		source := t.Source
+		if source == "" {
+			source = t.PathToManifest
+		}
+
+		a.Templates = append(a.Templates, AnsweredTemplate{
+			Source:   source,
+			Version:  t.manifest.Version,
+			Revision: t.Revision,
+		})
+	}
+
+	return a
+}
@@ -0,0 +1,108 @@
+package projector_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+func TestCreate_Answers(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	var (
+		p      = manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata"))
+		first  = t.TempDir()
+		second = t.TempDir()
+	)
+
+	_, err = projector.Create(context.Background(), projector.CreateConfig{
+		Config: &projector.Config{
+			WorkingDirectory: first,
+			ProjectName:      "app",
+			ProjectAuthor:    "tomakado",
+			OptionalSteps:    []string{"makefile"},
+		},
+		Provider:       p,
+		PathToManifest: "prompt",
+		Source:         "./templates/prompt",
+		Vars:           map[string]string{"db": "mysql", "docker": "true"},
+	})
+	require.NoError(t, err)
+
+	answers, err := projector.LoadAnswers(filepath.Join(first, projector.AnswersFilename))
+	require.NoError(t, err)
+	require.Equal(t, "app", answers.ProjectName)
+	require.Equal(t, "app", answers.ProjectPackage)
+	require.Equal(t, "tomakado", answers.ProjectAuthor)
+	require.Equal(t, []string{"makefile"}, answers.OptionalSteps)
+	require.Equal(t, []projector.AnsweredTemplate{{Source: "./templates/prompt", Version: "1.0.0"}}, answers.Templates)
+	require.Equal(t, map[string]string{"db": "mysql", "docker": "true", "port": "8080"}, answers.RawVars())
+
+	t.Run("answers replay generation", func(t *testing.T) {
+		_, err := projector.Create(context.Background(), projector.CreateConfig{
+			Config: &projector.Config{
+				WorkingDirectory: second,
+				ProjectName:      answers.ProjectName,
+				ProjectPackage:   answers.ProjectPackage,
+				ProjectAuthor:    answers.ProjectAuthor,
+				OptionalSteps:    answers.OptionalSteps,
+			},
+			Provider:       p,
+			PathToManifest: "prompt",
+			Source:         answers.Templates[0].Source,
+			Vars:           answers.RawVars(),
+		})
+		require.NoError(t, err)
+
+		for _, name := range []string{"README.md", "Makefile", projector.AnswersFilename} {
+			expected, err := os.ReadFile(filepath.Join(first, name))
+			require.NoError(t, err)
+
+			actual, err := os.ReadFile(filepath.Join(second, name))
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(actual), name)
+		}
+	})
+
+	t.Run("answers are not written in dry run or if skipped", func(t *testing.T) {
+		for _, cfg := range []projector.CreateConfig{
+			{Config: &projector.Config{DryRun: true}},
+			{Config: &projector.Config{}, SkipAnswers: true},
+		} {
+			wd := t.TempDir()
+
+			cfg.Config.WorkingDirectory = wd
+			cfg.Config.ProjectName = "app"
+			cfg.Config.ProjectAuthor = "tomakado"
+			cfg.Provider = p
+			cfg.PathToManifest = "prompt"
+
+			_, err := projector.Create(context.Background(), cfg)
+			require.NoError(t, err)
+			require.NoFileExists(t, filepath.Join(wd, projector.AnswersFilename))
+		}
+	})
+}
+
+func TestAnswers_RawVars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), projector.AnswersFilename)
+	answers := &projector.Answers{
+		Templates: []projector.AnsweredTemplate{{Source: "go/hello-world", Version: "1.0.0"}},
+		Vars: map[string]interface{}{
+			"tags":  []string{"api", "grpc"},
+			"port":  8080,
+			"debug": false,
+		},
+	}
+	require.NoError(t, answers.Save(path))
+
+	loaded, err := projector.LoadAnswers(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"tags": "api,grpc", "port": "8080", "debug": "false"}, loaded.RawVars())
+}
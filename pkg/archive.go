@@ -0,0 +1,199 @@
+package projector
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Archive formats supported by ArchiveOutput.
+const (
+	ArchiveTar = "tar"
+	ArchiveZip = "zip"
+)
+
+// ArchiveOutput keeps generated project in memory and writes it as tar or zip archive on Close,
+// so project can be sent over network without touching disk. Paths outside of archive root are rejected.
+type ArchiveOutput struct {
+	*MemOutput
+
+	w      io.Writer
+	format string
+}
+
+// NewTarOutput returns output writing uncompressed tar archive into w. Wrap w with gzip.Writer to compress it.
+func NewTarOutput(w io.Writer) *ArchiveOutput {
+	return &ArchiveOutput{MemOutput: NewMemOutput(), w: w, format: ArchiveTar}
+}
+
+// NewZipOutput returns output writing zip archive into w.
+func NewZipOutput(w io.Writer) *ArchiveOutput {
+	return &ArchiveOutput{MemOutput: NewMemOutput(), w: w, format: ArchiveZip}
+}
+
+func (o *ArchiveOutput) MkdirAll(path string, perm fs.FileMode) error {
+	if err := checkArchivePath(path); err != nil {
+		return err
+	}
+
+	return o.MemOutput.MkdirAll(path, perm)
+}
+
+func (o *ArchiveOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := checkArchivePath(name); err != nil {
+		return err
+	}
+
+	return o.MemOutput.WriteFile(name, data, perm)
+}
+
+func (o *ArchiveOutput) symlink(name, target string) error {
+	if err := checkArchivePath(name); err != nil {
+		return err
+	}
+
+	return o.MemOutput.symlink(name, target)
+}
+
+// AddDir adds files, directories and symbolic links located in dir on disk to archive keeping their modes,
+// e.g. project generated with shell scripts in temporary directory. Symbolic links are archived as links,
+// not followed. Other special files, e.g. sockets, aren't supported.
+func (o *ArchiveOutput) AddDir(dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case info.IsDir():
+			return o.MkdirAll(name, info.Mode().Perm())
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return o.WriteFile(name, data, info.Mode().Perm())
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return o.symlink(name, target)
+		default:
+			return fmt.Errorf("archive %q: unsupported file type %s", name, info.Mode().Type())
+		}
+	})
+}
+
+// Close writes archive of all written files and directories into underlying writer.
+// It doesn't close underlying writer.
+func (o *ArchiveOutput) Close() error {
+	switch o.format {
+	case ArchiveTar:
+		return o.writeTar()
+	case ArchiveZip:
+		return o.writeZip()
+	default:
+		return fmt.Errorf("unknown archive format %q", o.format)
+	}
+}
+
+func (o *ArchiveOutput) writeTar() error {
+	tw := tar.NewWriter(o.w)
+	for _, p := range o.paths() {
+		e := o.entries[p]
+
+		// symbolic link is stored as header only
+		var link string
+		if e.Mode()&fs.ModeSymlink != 0 {
+			link = string(e.data)
+		}
+
+		header, err := tar.FileInfoHeader(e, link)
+		if err != nil {
+			return fmt.Errorf("archive %q: %w", p, err)
+		}
+		header.Name = archiveName(p, e.IsDir())
+
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("archive %q: %w", p, err)
+		}
+
+		if link != "" {
+			continue
+		}
+
+		if _, err := tw.Write(e.data); err != nil {
+			return fmt.Errorf("archive %q: %w", p, err)
+		}
+	}
+
+	return tw.Close()
+}
+
+func (o *ArchiveOutput) writeZip() error {
+	zw := zip.NewWriter(o.w)
+	for _, p := range o.paths() {
+		e := o.entries[p]
+
+		header, err := zip.FileInfoHeader(e)
+		if err != nil {
+			return fmt.Errorf("archive %q: %w", p, err)
+		}
+		header.Name = archiveName(p, e.IsDir())
+		// target of symbolic link is stored uncompressed as its content, as unzip expects
+		if e.Mode().IsRegular() {
+			header.Method = zip.Deflate
+		}
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("archive %q: %w", p, err)
+		}
+
+		if _, err := w.Write(e.data); err != nil {
+			return fmt.Errorf("archive %q: %w", p, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// archiveName returns slash-separated name of archive entry, names of directories end with slash.
+func archiveName(p string, isDir bool) string {
+	name := filepath.ToSlash(p)
+	if isDir {
+		name += "/"
+	}
+
+	return name
+}
+
+// checkArchivePath returns error if path is absolute or points outside of archive root.
+func checkArchivePath(name string) error {
+	p := path.Clean(filepath.ToSlash(name))
+	if filepath.IsAbs(name) || path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+		return fmt.Errorf("%q is outside of archive", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package projector_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+func TestCreate_Archive(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	type archiveEntry struct {
+		content string
+		mode    fs.FileMode
+	}
+
+	readTar := func(t *testing.T, archive []byte) map[string]archiveEntry {
+		t.Helper()
+
+		entries := map[string]archiveEntry{}
+		tr := tar.NewReader(bytes.NewReader(archive))
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return entries
+			}
+			require.NoError(t, err)
+
+			content, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			entries[header.Name] = archiveEntry{content: string(content), mode: header.FileInfo().Mode()}
+		}
+	}
+
+	readZip := func(t *testing.T, archive []byte) map[string]archiveEntry {
+		t.Helper()
+
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		require.NoError(t, err)
+
+		entries := map[string]archiveEntry{}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+
+			entries[f.Name] = archiveEntry{content: string(content), mode: f.Mode()}
+		}
+		return entries
+	}
+
+	type testCase struct {
+		name      string
+		newOutput func(w io.Writer) *projector.ArchiveOutput
+		read      func(t *testing.T, archive []byte) map[string]archiveEntry
+	}
+
+	testCases := []testCase{
+		{name: "tar", newOutput: projector.NewTarOutput, read: readTar},
+		{name: "zip", newOutput: projector.NewZipOutput, read: readZip},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				archive bytes.Buffer
+				output  = tc.newOutput(&archive)
+				wd      = filepath.Join(t.TempDir(), "app")
+			)
+
+			_, err := projector.Create(context.Background(), projector.CreateConfig{
+				Config: &projector.Config{
+					WorkingDirectory: wd,
+					ProjectName:      "app",
+					ProjectAuthor:    "tomakado",
+					Output:           output,
+				},
+				Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+				PathToManifest: "archive",
+			})
+			require.NoError(t, err)
+			require.NoError(t, output.Close())
+
+			entries := tc.read(t, archive.Bytes())
+			require.Len(t, entries, 5)
+			require.Equal(t, archiveEntry{content: "# app\n", mode: 0o644}, entries["README.md"])
+			require.Equal(t, archiveEntry{content: "#!/bin/sh\necho run\n", mode: 0o755}, entries["bin/run.sh"])
+			require.Equal(t, archiveEntry{content: "TOKEN=\n", mode: 0o600}, entries["secret.env"])
+			require.True(t, entries["bin/"].mode.IsDir())
+			require.Contains(t, entries[projector.AnswersFilename].content, `source = "archive"`)
+
+			_, err = os.Stat(wd)
+			require.True(t, errors.Is(err, fs.ErrNotExist), "working directory is not created")
+		})
+	}
+
+	t.Run("shell scripts require output on disk", func(t *testing.T) {
+		_, err := projector.Create(context.Background(), projector.CreateConfig{
+			Config: &projector.Config{
+				ProjectName:   "app",
+				ProjectAuthor: "tomakado",
+				Output:        projector.NewZipOutput(io.Discard),
+			},
+			Provider:        manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+			PathToManifest:  "archive",
+			IncludeAllSteps: true,
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to execute shell script")
+	})
+
+	t.Run("paths outside of archive are rejected", func(t *testing.T) {
+		output := projector.NewTarOutput(io.Discard)
+		require.Error(t, output.WriteFile("../escape.txt", nil, 0o644))
+		require.Error(t, output.WriteFile("/etc/passwd", nil, 0o644))
+		require.Error(t, output.MkdirAll("a/../../b", 0o755))
+		require.NoError(t, output.WriteFile("a/../b.txt", nil, 0o644))
+	})
+}
+
+func TestArchiveOutput_AddDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# app\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "run.sh"), []byte("echo run\n"), 0o755))
+	// umask may drop permission bits on creation
+	require.NoError(t, os.Chmod(filepath.Join(dir, "bin"), 0o750))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "README.md"), 0o644))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "bin", "run.sh"), 0o755))
+
+	var archive bytes.Buffer
+	output := projector.NewTarOutput(&archive)
+	require.NoError(t, output.AddDir(dir))
+	require.NoError(t, output.Close())
+
+	modes := map[string]fs.FileMode{}
+	tr := tar.NewReader(&archive)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		modes[header.Name] = header.FileInfo().Mode()
+	}
+
+	require.Equal(t, map[string]fs.FileMode{
+		"README.md":  0o644,
+		"bin/":       fs.ModeDir | 0o750,
+		"bin/run.sh": 0o755,
+	}, modes)
+
+	t.Run("symbolic links are archived as links", func(t *testing.T) {
+		if err := os.Symlink("../README.md", filepath.Join(dir, "bin", "README.md")); err != nil {
+			t.Skipf("symbolic links are unavailable: %v", err)
+		}
+
+		var tarArchive bytes.Buffer
+		output := projector.NewTarOutput(&tarArchive)
+		require.NoError(t, output.AddDir(dir))
+		require.NoError(t, output.Close())
+
+		var link *tar.Header
+		tr := tar.NewReader(&tarArchive)
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			if header.Name == "bin/README.md" {
+				link = header
+			}
+		}
+		require.NotNil(t, link)
+		require.Equal(t, byte(tar.TypeSymlink), link.Typeflag)
+		require.Equal(t, "../README.md", link.Linkname)
+
+		var zipArchive bytes.Buffer
+		output = projector.NewZipOutput(&zipArchive)
+		require.NoError(t, output.AddDir(dir))
+		require.NoError(t, output.Close())
+
+		zr, err := zip.NewReader(bytes.NewReader(zipArchive.Bytes()), int64(zipArchive.Len()))
+		require.NoError(t, err)
+
+		var found bool
+		for _, f := range zr.File {
+			if f.Name != "bin/README.md" {
+				continue
+			}
+			found = true
+			require.NotZero(t, f.Mode()&fs.ModeSymlink, "mode of link: %s", f.Mode())
+
+			rc, err := f.Open()
+			require.NoError(t, err)
+			target, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			require.Equal(t, "../README.md", string(target))
+		}
+		require.True(t, found, "link is archived")
+	})
+
+	t.Run("special files aren't supported", func(t *testing.T) {
+		dir := t.TempDir()
+		listener, err := net.Listen("unix", filepath.Join(dir, "app.sock"))
+		if err != nil {
+			t.Skipf("unix sockets are unavailable: %v", err)
+		}
+		defer listener.Close() //nolint:errcheck
+
+		err = projector.NewZipOutput(io.Discard).AddDir(dir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported file type")
+	})
+}
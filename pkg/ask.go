@@ -0,0 +1,138 @@
+package projector
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tomakado/projector/internal/pkg/verbose"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+// Asker requests values missing in config from user, e.g. interactively in terminal.
+type Asker interface {
+	String(label, def string, validate func(string) error) (string, error)
+	Confirm(label string, def bool) (bool, error)
+	Select(label string, options []string, def string) (string, error)
+	MultiSelect(label string, options []string) ([]string, error)
+}
+
+// askMissing fills project info, template variables and optional steps not passed by user
+// with answers obtained from asker and asks for confirmation before generation.
+func askMissing(cfg *CreateConfig, m *manifest.Manifest, defaultAuthor string) error {
+	verbose.Println("asking user for missing values")
+
+	var (
+		c   = *cfg.Config
+		err error
+	)
+
+	if c.ProjectName == "" {
+		c.ProjectName, err = cfg.Asker.String("Project name", filepath.Base(c.WorkingDirectory), requireValue)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.ProjectPackage == "" {
+		if c.ProjectPackage, err = cfg.Asker.String("Project package", c.ProjectName, requireValue); err != nil {
+			return err
+		}
+	}
+
+	if c.ProjectAuthor == "" {
+		if c.ProjectAuthor, err = cfg.Asker.String("Project author", defaultAuthor, requireValue); err != nil {
+			return err
+		}
+	}
+
+	vars := make(map[string]string, len(m.Variables))
+	for name, value := range cfg.Vars {
+		vars[name] = value
+	}
+
+	for _, v := range m.Variables {
+		if _, ok := vars[v.Name]; ok {
+			continue
+		}
+
+		answer, err := askVariable(cfg.Asker, v)
+		if err != nil {
+			return fmt.Errorf("variable %q: %w", v.Name, err)
+		}
+		vars[v.Name] = answer
+	}
+
+	if !cfg.IncludeAllSteps && len(c.OptionalSteps) == 0 {
+		var optionalSteps []string
+		for _, step := range m.Steps {
+			if step.IsOptional {
+				optionalSteps = append(optionalSteps, step.Name)
+			}
+		}
+
+		if len(optionalSteps) > 0 {
+			if c.OptionalSteps, err = cfg.Asker.MultiSelect("Optional steps", optionalSteps); err != nil {
+				return err
+			}
+		}
+	}
+
+	ok, err := cfg.Asker.Confirm(fmt.Sprintf("Generate %q in %q?", c.ProjectName, c.WorkingDirectory), true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAborted
+	}
+
+	*cfg.Config = c
+	cfg.Vars = vars
+
+	return nil
+}
+
+func askVariable(asker Asker, v manifest.Variable) (string, error) {
+	label := v.Name
+	if v.Description != "" {
+		label = fmt.Sprintf("%s (%s)", v.Description, v.Name)
+	}
+
+	def, err := v.DefaultValue()
+	if err != nil {
+		return "", err
+	}
+
+	switch v.Kind() {
+	case manifest.VariableTypeBool:
+		answer, err := asker.Confirm(label, def.(bool))
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(answer), nil
+	case manifest.VariableTypeEnum:
+		return asker.Select(label, v.Options, def.(string))
+	}
+
+	return asker.String(label, formatValue(def), func(answer string) error {
+		_, err := v.Parse(answer)
+		return err
+	})
+}
+
+func formatValue(value interface{}) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ",")
+	}
+
+	return fmt.Sprint(value)
+}
+
+func requireValue(answer string) error {
+	if answer == "" {
+		return fmt.Errorf("value is required")
+	}
+
+	return nil
+}
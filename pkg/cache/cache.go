@@ -0,0 +1,282 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tomakado/projector/internal/pkg/verbose"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+const (
+	// DirEnv is environment variable that overrides location of cache directory.
+	DirEnv = "PROJECTOR_CACHE_DIR"
+
+	templatesDir   = "templates"
+	entryFilename  = "entry.toml"
+	cloneDir       = "repo"
+	defaultVersion = "_default"
+)
+
+// ErrNotCached is returned when requested template is not present in cache.
+var ErrNotCached = errors.New("template is not cached")
+
+// Cache stores templates fetched from remote sources on local disk, so they can be used offline.
+// Templates are stored under <root>/templates/<key>, where key is hash of repository url, subdirectory
+// and git ref, so different sources never share directory. Source itself is kept in entry file.
+type Cache struct {
+	root string
+}
+
+func New(root string) *Cache {
+	verbose.Printf("initialized template cache in %q", root)
+	return &Cache{root: root}
+}
+
+// DefaultDir returns directory for cache: value of PROJECTOR_CACHE_DIR if it is set or
+// "projector" inside user cache directory ($XDG_CACHE_HOME or ~/.cache on Linux).
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate user cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "projector"), nil
+}
+
+// Entry describes template stored in cache.
+type Entry struct {
+	Source    string    `toml:"source"`
+	Version   string    `toml:"version"`
+	Revision  string    `toml:"revision"`
+	FetchedAt time.Time `toml:"fetched_at"`
+
+	// Dir is directory containing template manifest.
+	Dir string `toml:"-"`
+}
+
+// Provider returns provider of template files stored in cache.
+func (e *Entry) Provider() *manifest.RealFSProvider {
+	return manifest.NewRealFSProvider(e.Dir)
+}
+
+// Get returns cached template by source. ErrNotCached is returned if template was never fetched.
+func (c *Cache) Get(source string) (*Entry, error) {
+	s, err := manifest.ParseGitSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.readEntry(c.entryDir(s), s)
+}
+
+// Add fetches template into cache. Already cached template is returned as is.
+func (c *Cache) Add(ctx context.Context, source string) (*Entry, error) {
+	e, err := c.Get(source)
+	if err == nil {
+		verbose.Printf("template %q is already cached", source)
+		return e, nil
+	}
+
+	if !errors.Is(err, ErrNotCached) {
+		return nil, err
+	}
+
+	return c.Update(ctx, source)
+}
+
+// Update fetches template again replacing cached copy. Cached copy is kept if fetching fails or ctx is done.
+func (c *Cache) Update(ctx context.Context, source string) (*Entry, error) {
+	s, err := manifest.ParseGitSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := c.entryDir(s)
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return nil, fmt.Errorf("init cache dir: %w", err)
+	}
+
+	staging, err := os.MkdirTemp(filepath.Dir(dir), ".fetch-")
+	if err != nil {
+		return nil, fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(staging) //nolint:errcheck
+
+	verbose.Printf("fetching %q into cache", source)
+	if err := s.Clone(ctx, filepath.Join(staging, cloneDir)); err != nil {
+		return nil, err
+	}
+
+	revision, err := manifest.GitRevision(ctx, filepath.Join(staging, cloneDir))
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Entry{
+		Source:    s.String(),
+		Version:   version(s),
+		Revision:  revision,
+		FetchedAt: time.Now().UTC(),
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(e); err != nil {
+		return nil, fmt.Errorf("encode cache entry: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(staging, entryFilename), buf.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("write cache entry: %w", err)
+	}
+
+	if err := replaceDir(dir, staging); err != nil {
+		return nil, err
+	}
+
+	e.Dir = templateDir(dir, s)
+	return e, nil
+}
+
+// Remove deletes template from cache.
+func (c *Cache) Remove(source string) error {
+	s, err := manifest.ParseGitSource(source)
+	if err != nil {
+		return err
+	}
+
+	dir := c.entryDir(s)
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%q: %w", source, ErrNotCached)
+	}
+
+	verbose.Printf("removing %q", dir)
+	return os.RemoveAll(dir)
+}
+
+// List returns all cached templates sorted by source.
+func (c *Cache) List() ([]Entry, error) {
+	var entries []Entry
+
+	root := filepath.Join(c.root, templatesDir)
+	dirs, err := os.ReadDir(root)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("list cache: %w", err)
+	}
+
+	for _, d := range dirs {
+		// staging directories of unfinished fetches start with dot
+		if !d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(root, d.Name())
+		if _, err := os.Stat(filepath.Join(path, entryFilename)); err != nil {
+			continue
+		}
+
+		e, err := c.readEntry(path, manifest.GitSource{})
+		if err != nil {
+			return nil, fmt.Errorf("list cache: %w", err)
+		}
+		entries = append(entries, *e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Source < entries[j].Source })
+	return entries, nil
+}
+
+// Clean removes all cached templates.
+func (c *Cache) Clean() error {
+	verbose.Printf("cleaning cache %q", c.root)
+	return os.RemoveAll(filepath.Join(c.root, templatesDir))
+}
+
+func (c *Cache) readEntry(dir string, s manifest.GitSource) (*Entry, error) {
+	bts, err := os.ReadFile(filepath.Join(dir, entryFilename))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%q: %w", s, ErrNotCached)
+		}
+		return nil, fmt.Errorf("read cache entry: %w", err)
+	}
+
+	var e Entry
+	if err := toml.Unmarshal(bts, &e); err != nil {
+		return nil, fmt.Errorf("parse cache entry %q: %w", dir, err)
+	}
+
+	if s.URL == "" {
+		if s, err = manifest.ParseGitSource(e.Source); err != nil {
+			return nil, fmt.Errorf("parse cache entry %q: %w", dir, err)
+		}
+	}
+
+	e.Dir = templateDir(dir, s)
+	return &e, nil
+}
+
+// replaceDir moves staging directory to dir. Outdated dir is moved aside first and restored
+// if staging directory can't be moved, so cached template isn't lost.
+func replaceDir(dir, staging string) error {
+	backup := staging + ".old"
+	if err := os.Rename(dir, backup); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("move outdated cache entry: %w", err)
+	}
+
+	if err := os.Rename(staging, dir); err != nil {
+		if restoreErr := os.Rename(backup, dir); restoreErr != nil && !errors.Is(restoreErr, fs.ErrNotExist) {
+			verbose.Printf("failed to restore outdated cache entry %q: %v", backup, restoreErr)
+		}
+		return fmt.Errorf("store cache entry: %w", err)
+	}
+
+	if err := os.RemoveAll(backup); err != nil {
+		verbose.Printf("failed to remove outdated cache entry %q: %v", backup, err)
+	}
+
+	return nil
+}
+
+func (c *Cache) entryDir(s manifest.GitSource) string {
+	return filepath.Join(c.root, templatesDir, sourceKey(s))
+}
+
+func templateDir(entryDir string, s manifest.GitSource) string {
+	return filepath.Join(entryDir, cloneDir, filepath.FromSlash(s.Subdir))
+}
+
+// sourceKey makes name of cache directory from repository url, subdirectory and ref. Parts are hashed
+// rather than joined, so e.g. repository with subdirectory and repository located in that subdirectory
+// or refs release/1.0 and release_1.0 don't share directory.
+func sourceKey(s manifest.GitSource) string {
+	h := sha256.New()
+	for _, part := range []string{s.URL, s.Subdir, s.Ref} {
+		// length prefix keeps boundaries between parts
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func version(s manifest.GitSource) string {
+	if s.Ref == "" {
+		return defaultVersion
+	}
+
+	return s.Ref
+}
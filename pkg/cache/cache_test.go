@@ -0,0 +1,145 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/projector/internal/pkg/gittest"
+	"github.com/tomakado/projector/pkg/cache"
+)
+
+func TestCache(t *testing.T) {
+	gittest.SkipIfNoGit(t)
+
+	var (
+		repo   = gittest.NewBareRepo(t)
+		c      = cache.New(t.TempDir())
+		latest = "git+file://" + repo.Bare + "//tpl"
+		tagged = "git+file://" + repo.Bare + "//tpl@v1.0.0"
+	)
+
+	t.Run("Get returns ErrNotCached for template never fetched", func(t *testing.T) {
+		e, err := c.Get(latest)
+		require.True(t, errors.Is(err, cache.ErrNotCached))
+		require.Nil(t, e)
+	})
+
+	t.Run("Add fetches templates by source and version", func(t *testing.T) {
+		for source, expected := range map[string]string{latest: "hello from v2\n", tagged: "hello from v1\n"} {
+			e, err := c.Add(context.Background(), source)
+			require.NoError(t, err)
+			require.Equal(t, source, e.Source)
+			require.Len(t, e.Revision, 40)
+
+			bts, err := e.Provider().Get(context.Background(), "hello.txt")
+			require.NoError(t, err)
+			require.Equal(t, expected, string(bts))
+		}
+	})
+
+	t.Run("cached template is used without fetching", func(t *testing.T) {
+		before, err := c.Get(latest)
+		require.NoError(t, err)
+
+		repo.Commit("tpl/hello.txt", "hello from v3\n")
+		repo.Push()
+
+		after, err := c.Add(context.Background(), latest)
+		require.NoError(t, err)
+		require.Equal(t, before.Revision, after.Revision)
+
+		bts, err := after.Provider().Get(context.Background(), "hello.txt")
+		require.NoError(t, err)
+		require.Equal(t, "hello from v2\n", string(bts))
+	})
+
+	t.Run("Update fetches template again", func(t *testing.T) {
+		e, err := c.Update(context.Background(), latest)
+		require.NoError(t, err)
+
+		bts, err := e.Provider().Get(context.Background(), "hello.txt")
+		require.NoError(t, err)
+		require.Equal(t, "hello from v3\n", string(bts))
+	})
+
+	t.Run("List returns all cached templates", func(t *testing.T) {
+		entries, err := c.List()
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, latest, entries[0].Source)
+		require.Equal(t, tagged, entries[1].Source)
+	})
+
+	t.Run("Remove deletes single template", func(t *testing.T) {
+		require.NoError(t, c.Remove(tagged))
+		require.True(t, errors.Is(c.Remove(tagged), cache.ErrNotCached))
+
+		entries, err := c.List()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+
+	t.Run("Clean deletes all templates", func(t *testing.T) {
+		require.NoError(t, c.Clean())
+
+		entries, err := c.List()
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+}
+
+func TestCache_DistinctSources(t *testing.T) {
+	gittest.SkipIfNoGit(t)
+
+	var (
+		repo = gittest.NewBareRepo(t)
+		c    = cache.New(t.TempDir())
+	)
+
+	repo.Branch("release/1.0", "tpl/hello.txt", "hello from release/1.0\n")
+	repo.Branch("release_1.0", "tpl/hello.txt", "hello from release_1.0\n")
+
+	sources := map[string]string{
+		"git+file://" + repo.Bare + "//tpl@release/1.0": "hello from release/1.0\n",
+		"git+file://" + repo.Bare + "//tpl@release_1.0": "hello from release_1.0\n",
+	}
+
+	for source := range sources {
+		_, err := c.Add(context.Background(), source)
+		require.NoError(t, err)
+	}
+
+	for source, expected := range sources {
+		e, err := c.Get(source)
+		require.NoError(t, err)
+		require.Equal(t, source, e.Source)
+
+		bts, err := e.Provider().Get(context.Background(), "hello.txt")
+		require.NoError(t, err)
+		require.Equal(t, expected, string(bts), source)
+	}
+
+	entries, err := c.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "release/1.0", entries[0].Version)
+
+	t.Run("failed update keeps cached copy", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(repo.Bare))
+
+		for source, expected := range sources {
+			_, err := c.Update(context.Background(), source)
+			require.Error(t, err)
+
+			e, err := c.Get(source)
+			require.NoError(t, err)
+
+			bts, err := e.Provider().Get(context.Background(), "hello.txt")
+			require.NoError(t, err)
+			require.Equal(t, expected, string(bts), source)
+		}
+	})
+}
@@ -11,4 +11,32 @@ type Config struct {
 	Manifest         *manifest.Manifest
 	OptionalSteps    []string
 	ManifestPath     string
+
+	// Vars contains resolved values of variables declared in manifest.
+	Vars map[string]interface{}
+
+	// DryRun makes generator render files and shell scripts without writing anything to disk
+	// or executing scripts. Planned files and commands are reported in Result.
+	DryRun bool
+
+	// Overwrite is policy applied to already existing output files, it takes precedence over policies declared
+	// by files. One of manifest.OverwritePolicies. If empty, policy of file is used, or generation fails if file
+	// has none.
+	Overwrite string
+
+	// KeepOnFailure leaves partially generated project in working directory if generation fails,
+	// so it can be inspected. Changes are undone otherwise.
+	KeepOnFailure bool
+
+	// Output receives generated files instead of working directory if set, e.g. to generate project into
+	// archive. Generation isn't staged then and shell scripts may be executed only if output is located on disk.
+	Output Output
+
+	// journal records changes of working directory. It is set while several templates are applied
+	// within the same transaction.
+	journal *journal
+
+	// memory receives generated files instead of disk if set. Existing files are not checked for conflicts then.
+	// It is used to render templates in memory during update.
+	memory *MemOutput
 }
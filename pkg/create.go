@@ -1,6 +1,7 @@
 package projector
 
 import (
+	"context"
 	"fmt"
 	"os/user"
 	"path/filepath"
@@ -14,16 +15,116 @@ type CreateConfig struct {
 	Provider        provider
 	IncludeAllSteps bool
 	PathToManifest  string
+
+	// Source identifies main template in answers file, e.g. name of builtin template or git source.
+	// PathToManifest is used if it is empty.
+	Source string
+
+	// Revision is git commit of main template if it is fetched from git repository.
+	Revision string
+
+	// SkipAnswers disables writing of answers file into working directory.
+	SkipAnswers bool
+
+	// Vars contains raw values of manifest variables passed by user, e.g. from command line.
+	Vars map[string]string
+
+	// Asker is used to request missing values from user. Values are not requested if Asker is nil.
+	Asker Asker
+
+	// With contains templates applied after the main one onto the same working directory with the same config.
+	With []Template
+
+	// Resolver locates templates extended by manifests by name. Names are resolved inside provider
+	// of extending template if it is nil.
+	Resolver manifest.Resolver
+}
+
+// Template couples location of template manifest with provider of template files.
+type Template struct {
+	Provider       provider
+	PathToManifest string
+
+	// Source identifies template in answers file. PathToManifest is used if it is empty.
+	Source string
+
+	// Revision is git commit of template if it is fetched from git repository.
+	Revision string
+}
+
+// Result describes project generated by Create.
+type Result struct {
+	Files []GeneratedFile
+
+	// Commands contains rendered shell scripts in order of execution.
+	Commands []string
+
+	// Steps contains executed steps in order of execution.
+	Steps []ExecutedStep
+}
+
+// loadedTemplate is template with its manifest loaded and validated.
+type loadedTemplate struct {
+	Template
+	manifest *manifest.Manifest
+}
+
+// Create loads manifests of main template and templates passed in With and applies them
+// one by one onto working directory. Variables and optional steps are shared between templates.
+// Error is returned if two templates produce the same file. Generation is interrupted if ctx is done and
+// working directory is restored to the state it had before generation, or removed if generation created it.
+func Create(ctx context.Context, cfg CreateConfig) (*Result, error) {
+	result, templates, err := create(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.SkipAnswers || cfg.Config.DryRun {
+		return result, nil
+	}
+
+	output := cfg.Config.Output
+	if output == nil {
+		output = NewDirOutput(cfg.Config.WorkingDirectory)
+	}
+
+	if err := newAnswers(cfg.Config, templates).save(output, AnswersFilename); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
-func Create(cfg CreateConfig) error {
-	m, err := manifest.Load(cfg.Provider, filepath.Join(cfg.PathToManifest, "projector.toml"))
+// create generates project and returns templates applied.
+func create(ctx context.Context, cfg CreateConfig) (*Result, []loadedTemplate, error) {
+	templates, err := loadTemplates(ctx, cfg.Resolver, append([]Template{{
+		Provider:       cfg.Provider,
+		PathToManifest: cfg.PathToManifest,
+		Source:         cfg.Source,
+		Revision:       cfg.Revision,
+	}}, cfg.With...))
 	if err != nil {
-		return fmt.Errorf("load manifest: %w", err)
+		return nil, nil, err
 	}
 
-	cfg.Config.ManifestPath = cfg.PathToManifest
-	cfg.Config.Manifest = m
+	var (
+		main     = templates[0]
+		combined = combineManifests(templates)
+	)
+
+	cfg.Config.ManifestPath = main.PathToManifest
+	cfg.Config.Manifest = main.manifest
+
+	if cfg.Asker != nil {
+		var defaultAuthor string
+		if u, err := user.Current(); err == nil {
+			defaultAuthor = u.Username
+		}
+
+		if err := askMissing(&cfg, combined, defaultAuthor); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	if cfg.Config.ProjectPackage == "" {
 		cfg.Config.ProjectPackage = cfg.Config.ProjectName
@@ -38,7 +139,7 @@ func Create(cfg CreateConfig) error {
 		u, err := user.Current()
 		if err != nil {
 			// TODO wrap custom typed error if possible
-			return fmt.Errorf("get current user: %w", err)
+			return nil, nil, fmt.Errorf("get current user: %w", err)
 		}
 		cfg.Config.ProjectAuthor = u.Username
 
@@ -48,9 +149,19 @@ func Create(cfg CreateConfig) error {
 		)
 	}
 
+	vars, err := combined.Variables.Resolve(cfg.Vars)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve variables: %w", err)
+	}
+	cfg.Config.Vars = vars
+
+	if err := manifest.ValidateOverwritePolicy(cfg.Config.Overwrite); err != nil {
+		return nil, nil, err
+	}
+
 	if cfg.IncludeAllSteps {
 		var optionalSteps []string
-		for _, step := range cfg.Config.Manifest.Steps {
+		for _, step := range combined.Steps {
 			if step.IsOptional {
 				stepToAppend := step.Name
 				optionalSteps = append(optionalSteps, stepToAppend)
@@ -59,5 +170,136 @@ func Create(cfg CreateConfig) error {
 		cfg.Config.OptionalSteps = optionalSteps
 	}
 
-	return Generate(cfg.Config, cfg.Provider)
+	var result *Result
+	if len(templates) == 1 {
+		g := NewGenerator(cfg.Config, cfg.Provider)
+		g.asker = cfg.Asker
+
+		if err := g.Generate(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		result = &Result{Files: g.Files(), Commands: g.Commands(), Steps: g.Steps()}
+	} else {
+		if result, err = generateComposition(ctx, cfg.Config, cfg.Asker, templates); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return result, templates, nil
+}
+
+func loadTemplates(ctx context.Context, resolve manifest.Resolver, templates []Template) ([]loadedTemplate, error) {
+	loaded := make([]loadedTemplate, 0, len(templates))
+	for _, t := range templates {
+		m, err := manifest.Load(ctx, t.Provider, filepath.Join(t.PathToManifest, "projector.toml"), resolve)
+		if err != nil {
+			return nil, fmt.Errorf("load manifest %q: %w", t.PathToManifest, err)
+		}
+
+		loaded = append(loaded, loadedTemplate{Template: t, manifest: m})
+	}
+
+	return loaded, nil
+}
+
+// combineManifests makes manifest containing variables and steps of all templates.
+// If several templates declare variable with the same name, the first declaration is used.
+func combineManifests(templates []loadedTemplate) *manifest.Manifest {
+	var (
+		combined = *templates[0].manifest
+		declared = map[string]struct{}{}
+	)
+
+	combined.Variables = nil
+	combined.Steps = nil
+
+	for _, t := range templates {
+		for _, v := range t.manifest.Variables {
+			if _, ok := declared[v.Name]; ok {
+				continue
+			}
+
+			declared[v.Name] = struct{}{}
+			combined.Variables = append(combined.Variables, v)
+		}
+
+		combined.Steps = append(combined.Steps, t.manifest.Steps...)
+	}
+
+	return &combined
+}
+
+// generateComposition applies templates one by one onto working directory.
+// Each template gets its own copy of config with only optional steps declared by its manifest.
+func generateComposition(
+	ctx context.Context, config *Config, asker Asker, templates []loadedTemplate,
+) (*Result, error) {
+	for _, stepName := range config.OptionalSteps {
+		var found bool
+		for _, t := range templates {
+			if _, err := t.manifest.Steps.Get(stepName); err == nil {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown step %q", stepName)
+		}
+	}
+
+	var (
+		result  Result
+		origins = map[string]fileOrigin{}
+	)
+
+	// apply generates templates one by one through journal of transaction, journal is nil in dry run
+	// or if output is passed in config
+	apply := func(j *journal) error {
+		for i, t := range templates {
+			verbose.Printf("applying template %q", t.manifest.Name)
+
+			c := *config
+			c.Manifest = t.manifest
+			c.ManifestPath = t.PathToManifest
+			c.OptionalSteps = nil
+			c.journal = j
+
+			for _, stepName := range config.OptionalSteps {
+				if _, err := t.manifest.Steps.Get(stepName); err == nil {
+					c.OptionalSteps = append(c.OptionalSteps, stepName)
+				}
+			}
+
+			g := NewGenerator(&c, t.Provider)
+			g.origins = origins
+			g.templateIndex = i
+			g.asker = asker
+
+			if err := g.Generate(ctx); err != nil {
+				return fmt.Errorf("[template %q] %w", t.manifest.Name, err)
+			}
+
+			result.Files = append(result.Files, g.Files()...)
+			result.Commands = append(result.Commands, g.Commands()...)
+			result.Steps = append(result.Steps, g.Steps()...)
+		}
+
+		return nil
+	}
+
+	var err error
+	if config.DryRun || config.Output != nil {
+		// working directory isn't changed, so there is nothing to undo
+		err = apply(nil)
+	} else {
+		err = stage(config.WorkingDirectory, config.KeepOnFailure, apply)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
 }
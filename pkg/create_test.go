@@ -0,0 +1,588 @@
+package projector_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+// fakeAsker answers with values from answers map by label or with defaults if label is not there.
+type fakeAsker struct {
+	answers       map[string]string
+	optionalSteps []string
+	generate      bool
+	asked         []string
+}
+
+func (a *fakeAsker) String(label, def string, validate func(string) error) (string, error) {
+	a.asked = append(a.asked, label)
+	if answer, ok := a.answers[label]; ok {
+		return answer, validate(answer)
+	}
+	return def, nil
+}
+
+func (a *fakeAsker) Confirm(label string, def bool) (bool, error) {
+	a.asked = append(a.asked, label)
+	if strings.HasPrefix(label, "Generate") {
+		return a.generate, nil
+	}
+	if answer, ok := a.answers[label]; ok {
+		return answer == "true", nil
+	}
+	return def, nil
+}
+
+func (a *fakeAsker) Select(label string, options []string, def string) (string, error) {
+	a.asked = append(a.asked, label)
+	if answer, ok := a.answers[label]; ok {
+		return answer, nil
+	}
+	return def, nil
+}
+
+func (a *fakeAsker) MultiSelect(label string, options []string) ([]string, error) {
+	a.asked = append(a.asked, label)
+	return a.optionalSteps, nil
+}
+
+func TestCreate_Asker(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	t.Run("missing values are requested and passed values are not", func(t *testing.T) {
+		var (
+			wd    = t.TempDir()
+			asker = &fakeAsker{
+				answers: map[string]string{
+					"Project name":         "asked-app",
+					"Database engine (db)": "mysql",
+					"docker":               "true",
+					"Project author":       "keanu.reeves@arasaka.net",
+				},
+				optionalSteps: []string{"makefile"},
+				generate:      true,
+			}
+			cfg = &projector.Config{WorkingDirectory: wd, ProjectPackage: "github.com/tomakado/asked-app"}
+		)
+
+		_, err := projector.Create(context.Background(), projector.CreateConfig{
+			Config:         cfg,
+			Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+			PathToManifest: "prompt",
+			Vars:           map[string]string{"port": "3000"},
+			Asker:          asker,
+		})
+		require.NoError(t, err)
+
+		require.NotContains(t, asker.asked, "Project package")
+		require.NotContains(t, asker.asked, "port")
+
+		readme, err := os.ReadFile(filepath.Join(wd, "README.md"))
+		require.NoError(t, err)
+		require.Equal(t, "# asked-app by keanu.reeves@arasaka.net\n\nmysql:3000 docker=true\n", string(readme))
+		require.FileExists(t, filepath.Join(wd, "Makefile"))
+	})
+
+	t.Run("declined confirmation aborts generation", func(t *testing.T) {
+		wd := filepath.Join(t.TempDir(), "declined")
+
+		_, err := projector.Create(context.Background(), projector.CreateConfig{
+			Config:         &projector.Config{WorkingDirectory: wd},
+			Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+			PathToManifest: "prompt",
+			Asker:          &fakeAsker{generate: false},
+		})
+		require.True(t, errors.Is(err, projector.ErrAborted))
+		require.NoDirExists(t, wd)
+	})
+}
+
+func TestCreate_With(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	p := manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata", "compose"))
+
+	t.Run("templates are applied onto the same directory", func(t *testing.T) {
+		wd := t.TempDir()
+
+		result, err := projector.Create(context.Background(), projector.CreateConfig{
+			Config: &projector.Config{
+				WorkingDirectory: wd,
+				ProjectName:      "svc",
+				ProjectAuthor:    "tomakado",
+				OptionalSteps:    []string{"compose"},
+			},
+			Provider:       p,
+			PathToManifest: "service",
+			With:           []projector.Template{{Provider: p, PathToManifest: "docker"}},
+		})
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			[]projector.GeneratedFile{
+				{Path: "README.md", Template: "compose/service", Size: 6, Mode: 0o644},
+				{Path: "Dockerfile", Template: "compose/docker", Size: 24, Mode: 0o644},
+				{Path: "docker-compose.yml", Template: "compose/docker", Size: 20, Mode: 0o644},
+			},
+			result.Files,
+		)
+
+		// variable declared by both templates gets value declared first
+		dockerfile, err := os.ReadFile(filepath.Join(wd, "Dockerfile"))
+		require.NoError(t, err)
+		require.Equal(t, "FROM golang\nEXPOSE 8080\n", string(dockerfile))
+	})
+
+	t.Run("templates are applied onto passed output", func(t *testing.T) {
+		var (
+			output = projector.NewMemOutput()
+			wd     = filepath.Join(t.TempDir(), "svc")
+		)
+
+		_, err := projector.Create(context.Background(), projector.CreateConfig{
+			Config: &projector.Config{
+				WorkingDirectory: wd,
+				ProjectName:      "svc",
+				ProjectAuthor:    "tomakado",
+				Output:           output,
+			},
+			Provider:       p,
+			PathToManifest: "service",
+			With:           []projector.Template{{Provider: p, PathToManifest: "docker"}},
+		})
+		require.NoError(t, err)
+		require.Contains(t, output.Files(), "Dockerfile")
+
+		_, err = os.Stat(wd)
+		require.True(t, errors.Is(err, fs.ErrNotExist), "working directory is not created")
+	})
+
+	t.Run("file collision between templates", func(t *testing.T) {
+		result, err := projector.Create(context.Background(), projector.CreateConfig{
+			Config: &projector.Config{
+				WorkingDirectory: t.TempDir(),
+				ProjectName:      "svc",
+				ProjectAuthor:    "tomakado",
+			},
+			Provider:       p,
+			PathToManifest: "service",
+			With:           []projector.Template{{Provider: p, PathToManifest: "readme"}},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `"README.md" is produced by both "compose/service" and "compose/readme"`)
+		require.Nil(t, result)
+	})
+
+	t.Run("file collision between templates with the same name", func(t *testing.T) {
+		for _, with := range []string{"service", "fork"} {
+			result, err := projector.Create(context.Background(), projector.CreateConfig{
+				Config: &projector.Config{
+					WorkingDirectory: t.TempDir(),
+					ProjectName:      "svc",
+					ProjectAuthor:    "tomakado",
+				},
+				Provider:       p,
+				PathToManifest: "service",
+				With:           []projector.Template{{Provider: p, PathToManifest: with}},
+			})
+			require.Error(t, err, with)
+			require.Contains(
+				t,
+				err.Error(),
+				`"README.md" is produced by both template #1 and template #2 named "compose/service"`,
+				with,
+			)
+			require.Nil(t, result)
+		}
+	})
+
+	t.Run("unknown optional step", func(t *testing.T) {
+		_, err := projector.Create(context.Background(), projector.CreateConfig{
+			Config: &projector.Config{
+				WorkingDirectory: t.TempDir(),
+				ProjectName:      "svc",
+				ProjectAuthor:    "tomakado",
+				OptionalSteps:    []string{"kubernetes"},
+			},
+			Provider:       p,
+			PathToManifest: "service",
+			With:           []projector.Template{{Provider: p, PathToManifest: "docker"}},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestCreate_Extends(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	wd := t.TempDir()
+
+	_, err = projector.Create(context.Background(), projector.CreateConfig{
+		Config: &projector.Config{
+			WorkingDirectory: wd,
+			ProjectName:      "svc",
+			ProjectAuthor:    "tomakado",
+		},
+		Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+		PathToManifest: "extends/service",
+	})
+	require.NoError(t, err)
+
+	// inherited step reads files from directory of extended template
+	mainGo, err := os.ReadFile(filepath.Join(wd, "main.go"))
+	require.NoError(t, err)
+	require.Equal(t, "package main\n\n// svc\nfunc main() {}\n", string(mainGo))
+
+	// overridden step reads files from directory of extending template
+	readme, err := os.ReadFile(filepath.Join(wd, "README.md"))
+	require.NoError(t, err)
+	require.Equal(t, "# svc service\n", string(readme))
+}
+
+func TestCreate_ExtendsResolvedTemplate(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	var (
+		wd       = t.TempDir()
+		embedded = manifest.NewEmbedFSProvider(&embeddedTestData, "testdata/embed/")
+	)
+
+	result, err := projector.Create(context.Background(), projector.CreateConfig{
+		Config: &projector.Config{
+			WorkingDirectory: wd,
+			ProjectName:      "svc",
+			ProjectAuthor:    "tomakado",
+		},
+		Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+		PathToManifest: "extends/builtin",
+		Resolver: func(_ context.Context, name string) (manifest.Provider, string, error) {
+			return embedded, name, nil
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 2)
+
+	// inherited step reads files from builtin template
+	mainGo, err := os.ReadFile(filepath.Join(wd, "main.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(mainGo), `fmt.Println("Hello, tomakado! This is svc!")`)
+
+	// own step reads files from local template
+	readme, err := os.ReadFile(filepath.Join(wd, "README.md"))
+	require.NoError(t, err)
+	require.Equal(t, "# svc from builtin\n", string(readme))
+}
+
+func TestCreate_DryRun(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	wd := filepath.Join(t.TempDir(), "app")
+
+	result, err := projector.Create(context.Background(), projector.CreateConfig{
+		Config: &projector.Config{
+			WorkingDirectory: wd,
+			ProjectName:      "my-app",
+			ProjectAuthor:    "tomakado",
+			DryRun:           true,
+		},
+		Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+		PathToManifest: "dry-run",
+	})
+	require.NoError(t, err)
+
+	require.Equal(
+		t,
+		[]projector.GeneratedFile{{Path: "cmd/my-app/main.go", Template: "dry-run", Size: 24, Mode: 0o644}},
+		result.Files,
+	)
+	require.Equal(t, []string{"touch my_app.txt"}, result.Commands)
+	require.Equal(t, []projector.ExecutedStep{{Name: "bootstrap", Template: "dry-run"}}, result.Steps)
+
+	// nothing is written and working directory is not changed
+	require.NoDirExists(t, wd)
+	actualWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+	require.Equal(t, startWorkingDirectory, actualWorkingDirectory)
+}
+
+func TestCreate_Delims(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	wd := t.TempDir()
+
+	_, err = projector.Create(context.Background(), projector.CreateConfig{
+		Config: &projector.Config{
+			WorkingDirectory: wd,
+			ProjectName:      "app",
+			ProjectAuthor:    "tomakado",
+		},
+		Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+		PathToManifest: "delims",
+	})
+	require.NoError(t, err)
+
+	for name, expected := range map[string]string{
+		"chart/app/values.yaml": "name: app\nimage: {{ .Values.image }}\n",
+		"README.md":             "# app [[ .ProjectName ]]\n",
+		"shell.txt":             "app",
+	} {
+		actual, err := os.ReadFile(filepath.Join(wd, name))
+		require.NoError(t, err)
+		require.Equal(t, expected, string(actual), name)
+	}
+}
+
+func TestCreate_Transaction(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	create := func(wd string, fail, keepOnFailure bool) error {
+		vars := map[string]string{}
+		if fail {
+			vars["fail"] = "true"
+		}
+
+		_, err := projector.Create(context.Background(), projector.CreateConfig{
+			Config: &projector.Config{
+				WorkingDirectory: wd,
+				ProjectName:      "app",
+				ProjectAuthor:    "tomakado",
+				KeepOnFailure:    keepOnFailure,
+			},
+			Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+			PathToManifest: "transaction",
+			SkipAnswers:    true,
+			Vars:           vars,
+		})
+		return err
+	}
+
+	entries := func(t *testing.T, dir string) []string {
+		t.Helper()
+
+		dirEntries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+
+		var names []string
+		for _, e := range dirEntries {
+			names = append(names, e.Name())
+		}
+		return names
+	}
+
+	t.Run("new working directory is not created on failure", func(t *testing.T) {
+		parent := t.TempDir()
+		require.Error(t, create(filepath.Join(parent, "app"), true, false))
+		require.Empty(t, entries(t, parent))
+	})
+
+	t.Run("changes of existing working directory are undone on failure", func(t *testing.T) {
+		parent := t.TempDir()
+		wd := filepath.Join(parent, "app")
+		require.NoError(t, os.Mkdir(wd, 0o755))
+		writeFile(t, wd, "README.md", "hand-written\n")
+		require.NoError(t, os.Chmod(filepath.Join(wd, "README.md"), 0o600))
+		writeFile(t, wd, "obsolete.txt", "old\n")
+		writeFile(t, wd, "notes.txt", "mine\n")
+
+		modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, os.Chtimes(filepath.Join(wd, "notes.txt"), modTime, modTime))
+
+		require.Error(t, create(wd, true, false))
+		require.Equal(t, []string{"app"}, entries(t, parent))
+		require.Equal(t, []string{"README.md", "notes.txt", "obsolete.txt"}, entries(t, wd))
+
+		// files changed and removed by shell script are restored
+		for name, expected := range map[string]string{
+			"README.md":    "hand-written\n",
+			"notes.txt":    "mine\n",
+			"obsolete.txt": "old\n",
+		} {
+			actual, err := os.ReadFile(filepath.Join(wd, name))
+			require.NoError(t, err)
+			require.Equal(t, expected, string(actual), name)
+		}
+
+		info, err := os.Stat(filepath.Join(wd, "README.md"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		info, err = os.Stat(filepath.Join(wd, "notes.txt"))
+		require.NoError(t, err)
+		require.True(t, info.ModTime().Equal(modTime))
+	})
+
+	t.Run("partially generated project is kept on failure if requested", func(t *testing.T) {
+		parent := t.TempDir()
+		err := create(filepath.Join(parent, "app"), true, true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "partially generated project is kept in")
+
+		require.Equal(t, []string{"app"}, entries(t, parent))
+		require.Equal(t, []string{"README.md", "build", "docs", "notes.txt"}, entries(t, filepath.Join(parent, "app")))
+	})
+
+	t.Run("changes are made in working directory itself", func(t *testing.T) {
+		parent := t.TempDir()
+		wd := filepath.Join(parent, "app")
+		require.NoError(t, os.Mkdir(wd, 0o755))
+		writeFile(t, wd, "README.md", "hand-written\n")
+		writeFile(t, wd, "obsolete.txt", "old\n")
+		writeFile(t, wd, "notes.txt", "mine\n")
+		writeFile(t, wd, "untouched.txt", "untouched\n")
+
+		modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, os.Chtimes(filepath.Join(wd, "untouched.txt"), modTime, modTime))
+
+		require.NoError(t, create(wd, false, false))
+		require.Equal(t, []string{"app"}, entries(t, parent))
+		require.Equal(t, []string{"README.md", "build", "docs", "notes.txt", "untouched.txt"}, entries(t, wd))
+
+		for name, expected := range map[string]string{
+			"README.md":            "# app\n",
+			"docs/guide/README.md": "# app\n",
+			"notes.txt":            "mine\ngenerated\n",
+			"untouched.txt":        "untouched\n",
+		} {
+			actual, err := os.ReadFile(filepath.Join(wd, name))
+			require.NoError(t, err)
+			require.Equal(t, expected, string(actual), name)
+		}
+
+		info, err := os.Stat(filepath.Join(wd, "untouched.txt"))
+		require.NoError(t, err)
+		require.True(t, info.ModTime().Equal(modTime))
+
+		actualWorkingDirectory, err := os.Getwd()
+		require.NoError(t, err)
+		require.Equal(t, startWorkingDirectory, actualWorkingDirectory)
+	})
+}
+
+func TestCreate_Concurrent(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	var (
+		p    = manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata"))
+		wds  = make([]string, 8)
+		errs = make([]error, len(wds))
+		wg   sync.WaitGroup
+	)
+
+	for i := range wds {
+		wds[i] = filepath.Join(t.TempDir(), fmt.Sprintf("app-%d", i))
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			_, errs[i] = projector.Create(context.Background(), projector.CreateConfig{
+				Config: &projector.Config{
+					WorkingDirectory: wds[i],
+					ProjectName:      fmt.Sprintf("app-%d", i),
+					ProjectAuthor:    "tomakado",
+				},
+				Provider:       p,
+				PathToManifest: "transaction",
+				SkipAnswers:    true,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, wd := range wds {
+		require.NoError(t, errs[i])
+
+		// files are rendered and shell scripts are executed in working directory of each generation
+		for name, expected := range map[string]string{
+			"README.md": fmt.Sprintf("# app-%d\n", i),
+			"notes.txt": "generated\n",
+		} {
+			actual, err := os.ReadFile(filepath.Join(wd, name))
+			require.NoError(t, err)
+			require.Equal(t, expected, string(actual), name)
+		}
+	}
+
+	actualWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+	require.Equal(t, startWorkingDirectory, actualWorkingDirectory)
+}
+
+func TestCreate_Interrupted(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	parent := t.TempDir()
+	started := time.Now()
+
+	_, err = projector.Create(ctx, projector.CreateConfig{
+		Config: &projector.Config{
+			WorkingDirectory: filepath.Join(parent, "app"),
+			ProjectName:      "app",
+			ProjectAuthor:    "tomakado",
+		},
+		Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+		PathToManifest: "transaction",
+		SkipAnswers:    true,
+		Vars:           map[string]string{"wait": "true"},
+	})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Contains(t, err.Error(), `[step "wait"] interrupted`)
+	require.Less(t, time.Since(started), 5*time.Second)
+
+	entries, err := os.ReadDir(parent)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	t.Run("existing working directory is restored", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		wd := t.TempDir()
+		writeFile(t, wd, "notes.txt", "mine\n")
+
+		_, err = projector.Create(ctx, projector.CreateConfig{
+			Config: &projector.Config{
+				WorkingDirectory: wd,
+				ProjectName:      "app",
+				ProjectAuthor:    "tomakado",
+			},
+			Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+			PathToManifest: "transaction",
+			SkipAnswers:    true,
+			Vars:           map[string]string{"wait": "true"},
+		})
+		require.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+
+		entries, err := os.ReadDir(wd)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		notes, err := os.ReadFile(filepath.Join(wd, "notes.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "mine\n", string(notes))
+	})
+}
@@ -0,0 +1,11 @@
+package projector
+
+import "errors"
+
+var (
+	// ErrAborted is returned when user declines generation in interactive mode.
+	ErrAborted = errors.New("aborted by user")
+
+	// ErrFileExists is returned when output file already exists and overwrite policy is "fail".
+	ErrFileExists = errors.New("file already exists")
+)
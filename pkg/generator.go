@@ -2,27 +2,73 @@ package projector
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 	"text/template"
 
+	"github.com/tomakado/projector/internal/pkg/glob"
 	"github.com/tomakado/projector/internal/pkg/verbose"
 	"github.com/tomakado/projector/pkg/manifest"
 )
 
 type provider interface {
-	Get(filename string) ([]byte, error)
+	Get(ctx context.Context, filename string) ([]byte, error)
 }
 
+// lister is implemented by providers able to enumerate template files.
+// It is required to use glob patterns and directories as file paths.
+type lister interface {
+	List(ctx context.Context, dir string) ([]string, error)
+}
+
+// moder is implemented by providers able to report permission bits of template files.
+// Executable template files stay executable in generated project if provider implements it.
+type moder interface {
+	Mode(ctx context.Context, filename string) (fs.FileMode, error)
+}
+
+// templateSource is location of template files: provider and directory inside it.
+type templateSource struct {
+	provider provider
+	dir      string
+}
+
+// of returns location of files of passed step. Steps inherited from extended templates
+// keep directory and, if template is resolved by name, provider of extended template.
+func (f templateSource) of(step manifest.Step) templateSource {
+	if step.Dir != "" {
+		f.dir = step.Dir
+	}
+
+	if step.Provider != nil {
+		f.provider = step.Provider
+	}
+
+	return f
+}
+
+const (
+	// defaultFileMode and executableFileMode are used for files without explicit mode
+	// depending on whether source file is executable. Umask is applied on top of them.
+	defaultFileMode    fs.FileMode = 0o644
+	executableFileMode fs.FileMode = 0o755
+	dirMode            fs.FileMode = 0o755
+)
+
 // Generate traverses template manifest passed inside config and executes listed steps with config passed as context.
-func Generate(config *Config, provider provider) error {
+// Generation is interrupted if ctx is done, shell scripts being executed are killed then.
+func Generate(ctx context.Context, config *Config, provider provider) error {
 	verbose.Println("passing config and provider to new instance of *projector.Generator")
 	verbose.Printf("provider = %T, config = %+v", provider, *config)
 
-	return NewGenerator(config, provider).Generate()
+	return NewGenerator(config, provider).Generate(ctx)
 }
 
 // Generator couples generation task and file provider into unite context to create project.
@@ -32,36 +78,130 @@ type Generator struct {
 
 	// optionalSteps is string set with included optional step names
 	optionalSteps map[string]struct{}
+
+	// files contains files written by generator in order of writing.
+	files []GeneratedFile
+
+	// origins maps output paths to templates produced them. It may be shared
+	// between generators applying several templates onto the same working directory.
+	origins map[string]fileOrigin
+
+	// templateIndex is position of applied template among templates sharing origins.
+	templateIndex int
+
+	// commands contains rendered shell scripts in order of execution.
+	commands []string
+
+	// steps contains steps executed by generator in order of execution.
+	steps []ExecutedStep
+
+	// output receives generated files. It is journal of working directory,
+	// memory in dry run or output passed in config. Shell scripts are executed in directory of output.
+	output Output
+
+	// asker is used to resolve conflicts with existing files if overwrite policy is "prompt".
+	asker Asker
+}
+
+// ExecutedStep is manifest step executed by generator.
+type ExecutedStep struct {
+	Name string
+
+	// Template is name of template step belongs to.
+	Template string
+}
+
+// GeneratedFile is file written by generator.
+type GeneratedFile struct {
+	// Path is path to file relative to working directory.
+	Path string
+
+	// Template is name of template produced the file.
+	Template string
+
+	// Size is size of file content in bytes.
+	Size int
+
+	// Mode is permission bits of file.
+	Mode fs.FileMode
 }
 
 func NewGenerator(config *Config, provider provider) *Generator {
+	var output Output = NewDirOutput("")
+	switch {
+	case config == nil:
+		// generator without config writes files relative to current directory
+	case config.memory != nil:
+		output = config.memory
+	case config.DryRun:
+		output = NewMemOutput()
+	case config.Output != nil:
+		output = config.Output
+	default:
+		output = NewDirOutput(config.WorkingDirectory)
+	}
+
 	return &Generator{
 		config:        config,
 		provider:      provider,
 		optionalSteps: map[string]struct{}{},
+		origins:       map[string]fileOrigin{},
+		output:        output,
 	}
 }
 
 // Generate traverses steps in project template manifest and performs actions defined inside each of them.
-func (g *Generator) Generate() error {
+// Step being executed when ctx is done is reported in returned error, which wraps ctx.Err().
+func (g *Generator) Generate(ctx context.Context) error {
 	if err := g.makeOptionalStepSet(g.config.OptionalSteps); err != nil {
 		return fmt.Errorf("makeOptionalStepSet: %w", err)
 	}
 
-	verbose.Printf("initializing working directory %q", g.config.WorkingDirectory)
-	if err := os.MkdirAll(g.config.WorkingDirectory, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to mkdir %q: %w", g.config.WorkingDirectory, err)
+	if g.config.Vars == nil {
+		verbose.Println("variables are not resolved, using default values")
+
+		vars, err := g.config.Manifest.Variables.Resolve(nil)
+		if err != nil {
+			return fmt.Errorf("resolve variables: %w", err)
+		}
+		g.config.Vars = vars
 	}
 
-	verbose.Printf("cd %s", g.config.WorkingDirectory)
-	if err := os.Chdir(g.config.WorkingDirectory); err != nil {
-		return fmt.Errorf("failed to change working directory to %q: %w", g.config.WorkingDirectory, err)
+	switch {
+	case g.config.DryRun:
+		verbose.Println("dry run, working directory is left untouched")
+		return g.executeSteps(ctx)
+	case g.config.Output != nil:
+		verbose.Printf("writing generated files to %T", g.config.Output)
+		return g.executeSteps(ctx)
+	case g.config.journal != nil:
+		return g.executeStepsWith(ctx, g.config.journal)
+	default:
+		return stage(g.config.WorkingDirectory, g.config.KeepOnFailure, func(j *journal) error {
+			return g.executeStepsWith(ctx, j)
+		})
 	}
+}
+
+// executeStepsWith executes steps writing files through journal j, so they are undone if generation fails.
+func (g *Generator) executeStepsWith(ctx context.Context, j *journal) error {
+	verbose.Printf("generating in %q", j.Dir())
+
+	g.output = j
 
+	return g.executeSteps(ctx)
+}
+
+// executeSteps executes steps of manifest in directory of generator.
+func (g *Generator) executeSteps(ctx context.Context) error {
 	verbose.Println("traversing manifest steps")
 	for i, step := range g.config.Manifest.Steps {
 		verbose.Printf("step %q, %d of %d", step.Name, (i + 1), len(g.config.Manifest.Steps))
 
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("[step %q] interrupted: %w", step.Name, err)
+		}
+
 		if step.IsOptional {
 			if _, ok := g.optionalSteps[step.Name]; !ok {
 				verbose.Printf("step %q is optional and not included to config, skipping", step.Name)
@@ -70,38 +210,73 @@ func (g *Generator) Generate() error {
 			verbose.Printf("step %q is optional but included to config", step.Name)
 		}
 
+		step.Delims = step.Delims.Or(g.manifestDelims())
+
+		ok, err := g.evaluateWhen(step.When, step.Delims)
+		if err != nil {
+			return fmt.Errorf("[step %q] %w", step.Name, err)
+		}
+
+		if !ok {
+			verbose.Printf("condition of step %q is false, skipping", step.Name)
+			continue
+		}
+
 		if step.Files != nil {
-			if err := g.ProcessFiles(step.Files); err != nil {
-				return fmt.Errorf("[step %q] generate files: %w", step.Name, err)
+			if err := g.processFiles(ctx, g.stepSource(step), step.Files, step.Delims); err != nil {
+				return stepError(ctx, step, "generate files", err)
 			}
 		}
 
 		if strings.TrimSpace(step.Shell) != "" {
-			if err := g.RunShell(step.Shell); err != nil {
-				return fmt.Errorf("[step %q] run shell: %w", step.Name, err)
+			if err := g.runShell(ctx, step.Shell, step.Delims); err != nil {
+				return stepError(ctx, step, "run shell", err)
 			}
 		}
+
+		g.steps = append(g.steps, ExecutedStep{Name: step.Name, Template: g.config.Manifest.Name})
 	}
 
 	return nil
 }
 
-func (g *Generator) ProcessFiles(files []manifest.File) error {
+// stepError wraps error of step. Errors occurred after ctx is done are reported as interruption of step.
+func stepError(ctx context.Context, step manifest.Step, action string, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("[step %q] interrupted: %w", step.Name, ctx.Err())
+	}
+
+	return fmt.Errorf("[step %q] %s: %w", step.Name, action, err)
+}
+
+// ProcessFiles renders passed files located in template directory and writes them to output paths.
+func (g *Generator) ProcessFiles(ctx context.Context, files []manifest.File) error {
+	return g.processFiles(ctx, g.templateSource(), files, g.manifestDelims())
+}
+
+// processFiles renders and writes files, delims are used for files without own delimiters.
+func (g *Generator) processFiles(
+	ctx context.Context, src templateSource, files []manifest.File, delims manifest.Delims,
+) error {
 	verbose.Println("processing files")
 
+	files, err := g.expandFiles(ctx, src, files, delims)
+	if err != nil {
+		return err
+	}
+
 	for _, file := range files {
-		t, err := g.ExtractTemplateFrom(file.Path)
+		generated, err := g.renderFile(ctx, src, file)
 		if err != nil {
 			return err
 		}
 
-		verbose.Println("rendering file")
-		var generated bytes.Buffer
-		if err := t.Execute(&generated, g.config); err != nil {
-			return fmt.Errorf("generate file from template %q: %w", file.Path, err)
+		mode, isExplicit, err := g.fileMode(ctx, src, file)
+		if err != nil {
+			return err
 		}
 
-		if err := g.saveGeneratedFile(file, generated.Bytes()); err != nil {
+		if err := g.saveGeneratedFile(file, generated, mode, isExplicit); err != nil {
 			return err
 		}
 	}
@@ -109,16 +284,161 @@ func (g *Generator) ProcessFiles(files []manifest.File) error {
 	return nil
 }
 
+// renderFile reads source of file and renders it as template. Raw and binary files are returned as is.
+func (g *Generator) renderFile(ctx context.Context, from templateSource, file manifest.File) ([]byte, error) {
+	verbose.Printf("reading template file %q", file.Path)
+	src, err := from.provider.Get(ctx, filepath.Join(from.dir, file.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Raw || g.config.Manifest.IsRaw(filepath.ToSlash(file.Path)) || isBinary(src) {
+		verbose.Printf("%q is raw or binary file, copying as is", file.Path)
+		return src, nil
+	}
+
+	t, err := parseTemplate(file.Path, src, file.Delims)
+	if err != nil {
+		return nil, err
+	}
+
+	verbose.Println("rendering file")
+	var generated bytes.Buffer
+	if err := t.Execute(&generated, g.config); err != nil {
+		return nil, fmt.Errorf("generate file from template %q: %w", file.Path, err)
+	}
+
+	return generated.Bytes(), nil
+}
+
+// binarySniffLen is number of leading bytes checked by isBinary, the same as git does.
+const binarySniffLen = 8000
+
+// isBinary reports whether content looks like binary data, i.e. has NUL byte in its beginning.
+func isBinary(content []byte) bool {
+	if len(content) > binarySniffLen {
+		content = content[:binarySniffLen]
+	}
+
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// expandFiles replaces files having glob pattern or directory as path with files they match.
+func (g *Generator) expandFiles(
+	ctx context.Context, src templateSource, files []manifest.File, delims manifest.Delims,
+) ([]manifest.File, error) {
+	var expanded []manifest.File
+	for _, file := range files {
+		file.Delims = file.Delims.Or(delims)
+
+		ok, err := g.evaluateWhen(file.When, file.Delims)
+		if err != nil {
+			return nil, fmt.Errorf("file %q: %w", file.Path, err)
+		}
+
+		if !ok {
+			verbose.Printf("condition of file %q is false, skipping", file.Path)
+			continue
+		}
+
+		matched, err := g.expandFile(ctx, src, file)
+		if err != nil {
+			return nil, err
+		}
+
+		expanded = append(expanded, matched...)
+	}
+
+	return expanded, nil
+}
+
+func (g *Generator) expandFile(ctx context.Context, src templateSource, file manifest.File) ([]manifest.File, error) {
+	var (
+		l, isLister = src.provider.(lister)
+		isGlob      = glob.HasMeta(file.Path)
+		pattern     = path.Clean(filepath.ToSlash(file.Path))
+		root        = pattern
+	)
+
+	if !isLister {
+		if isGlob {
+			return nil, fmt.Errorf("expand %q: provider %T is unable to list files", file.Path, src.provider)
+		}
+		return []manifest.File{file}, nil
+	}
+
+	if isGlob {
+		root = glob.Base(pattern)
+	}
+
+	verbose.Printf("listing template files in %q", root)
+	names, err := l.List(ctx, filepath.Join(src.dir, root))
+	if err != nil {
+		if !isGlob && (errors.Is(err, manifest.ErrNotDirectory) || errors.Is(err, manifest.ErrFileNotFound)) {
+			return []manifest.File{file}, nil
+		}
+		return nil, fmt.Errorf("list files in %q: %w", root, err)
+	}
+
+	var matched []manifest.File
+	for _, name := range names {
+		filePath := path.Join(root, name)
+		if isGlob && !glob.Match(pattern, filePath) {
+			continue
+		}
+
+		if isExcluded(file.Exclude, name) {
+			verbose.Printf("%q is excluded, skipping", filePath)
+			continue
+		}
+
+		matched = append(matched, manifest.File{
+			Path:      filePath,
+			Output:    path.Join(file.Output, strings.TrimSuffix(name, file.TrimSuffix)),
+			Overwrite: file.Overwrite,
+			Mode:      file.Mode,
+			Raw:       file.Raw,
+			Delims:    file.Delims,
+		})
+	}
+
+	// typo in pattern or too broad excludes would silently produce nothing otherwise
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("%q matched no files", file.Path)
+	}
+
+	verbose.Printf("%q matched %d files", file.Path, len(matched))
+	return matched, nil
+}
+
+func isExcluded(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if glob.Match(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ExtractTemplateFrom reads plain text from specified file and tries to parse it as text/template syntax.
-func (g *Generator) ExtractTemplateFrom(filename string) (*template.Template, error) {
+func (g *Generator) ExtractTemplateFrom(ctx context.Context, filename string) (*template.Template, error) {
+	return g.extractTemplate(ctx, g.templateSource(), filename)
+}
+
+func (g *Generator) extractTemplate(ctx context.Context, src templateSource, filename string) (*template.Template, error) {
 	verbose.Printf("extracting file template from %q", filename)
-	tplBytes, err := g.provider.Get(filepath.Join(g.config.Manifest.Name, filename))
+	tplBytes, err := src.provider.Get(ctx, filepath.Join(src.dir, filename))
 	if err != nil {
 		return nil, err
 	}
 
+	return parseTemplate(filename, tplBytes, g.manifestDelims())
+}
+
+func parseTemplate(filename string, tplBytes []byte, delims manifest.Delims) (*template.Template, error) {
 	verbose.Println("parsing file template")
-	t, err := template.New(filename).Parse(string(tplBytes))
+	t, err := delims.NewTemplate(filename).Parse(string(tplBytes))
 	if err != nil {
 		// TODO wrap custom typed error
 		return nil, fmt.Errorf("parse template in %q: %w", filename, err)
@@ -127,33 +447,178 @@ func (g *Generator) ExtractTemplateFrom(filename string) (*template.Template, er
 	return t, nil
 }
 
-func (g *Generator) saveGeneratedFile(fileManifest manifest.File, data []byte) error {
+// templateDir returns directory of template inside provider. Manifest name is used
+// if config has no path to manifest, as builtin templates are stored under their names.
+func (g *Generator) templateDir() string {
+	if g.config.ManifestPath != "" {
+		return g.config.ManifestPath
+	}
+
+	return g.config.Manifest.Name
+}
+
+// manifestDelims returns template delimiters declared by manifest.
+func (g *Generator) manifestDelims() manifest.Delims {
+	if g.config == nil || g.config.Manifest == nil {
+		return nil
+	}
+
+	return g.config.Manifest.Delims
+}
+
+// templateSource returns location of files of template itself.
+func (g *Generator) templateSource() templateSource {
+	return templateSource{provider: g.provider, dir: g.templateDir()}
+}
+
+// stepSource returns location of template files of passed step, it differs from template
+// location for steps inherited from extended manifests.
+func (g *Generator) stepSource(step manifest.Step) templateSource {
+	return g.templateSource().of(step)
+}
+
+// fileMode returns mode declared for file in manifest or mode derived from source file.
+// True is returned if mode is declared explicitly.
+func (g *Generator) fileMode(ctx context.Context, src templateSource, f manifest.File) (fs.FileMode, bool, error) {
+	mode, ok, err := f.FileMode()
+	if err != nil || ok {
+		return mode, ok, err
+	}
+
+	m, isModer := src.provider.(moder)
+	if !isModer {
+		return defaultFileMode, false, nil
+	}
+
+	sourceMode, err := m.Mode(ctx, filepath.Join(src.dir, f.Path))
+	if err != nil {
+		return 0, false, err
+	}
+
+	if sourceMode&0o111 != 0 {
+		return executableFileMode, false, nil
+	}
+
+	return defaultFileMode, false, nil
+}
+
+// fileOrigin identifies template produced file.
+type fileOrigin struct {
+	// template is position of template among applied ones, names of templates may be equal.
+	template int
+	name     string
+}
+
+// collisionError reports file produced by template applied earlier.
+func (g *Generator) collisionError(outputPath string, origin fileOrigin) error {
+	if origin.name == g.config.Manifest.Name {
+		return fmt.Errorf(
+			"%q is produced by both template #%d and template #%d named %q",
+			outputPath,
+			origin.template+1,
+			g.templateIndex+1,
+			origin.name,
+		)
+	}
+
+	return fmt.Errorf("%q is produced by both %q and %q templates", outputPath, origin.name, g.config.Manifest.Name)
+}
+
+// saveGeneratedFile writes data to output path of file. Explicit mode is set regardless of umask,
+// umask is applied to derived one. Mode of overwritten file is replaced in both cases.
+func (g *Generator) saveGeneratedFile(fileManifest manifest.File, data []byte, mode fs.FileMode, isExplicitMode bool) error {
 	verbose.Printf("saving rendered file to %q", fileManifest.Output)
 	outputPath, err := g.RenderOutputPath(fileManifest)
 	if err != nil {
 		return err
 	}
 
+	outputPath = filepath.Clean(outputPath)
+	origin, isWritten := g.origins[outputPath]
+	if isWritten && origin.template != g.templateIndex {
+		return g.collisionError(outputPath, origin)
+	}
+
+	if !isWritten && g.config.memory == nil {
+		var write bool
+		if data, write, err = g.resolveConflict(fileManifest, outputPath, data); err != nil {
+			return err
+		}
+
+		if !write {
+			return nil
+		}
+	}
+
 	pathDir := filepath.Dir(outputPath)
 	verbose.Printf("mkdir %s", pathDir)
-	if err := os.MkdirAll(pathDir, os.ModePerm); err != nil {
+	if err := g.output.MkdirAll(pathDir, dirMode); err != nil {
 		// TODO wrap custom typed error
 		return fmt.Errorf("init dir %q: %w", pathDir, err)
 	}
 
+	// write keeps mode of existing file, so it is removed to be created again with umask applied
+	if info, err := g.output.Stat(outputPath); err == nil && !isExplicitMode && !info.IsDir() {
+		verbose.Printf("removing existing %q", outputPath)
+		if err := g.output.Remove(outputPath); err != nil {
+			return fmt.Errorf("remove existing %q: %w", outputPath, err)
+		}
+	}
+
 	verbose.Printf("writing rendered file to %q", outputPath)
-	if err := os.WriteFile(outputPath, data, os.ModePerm); err != nil {
+	if err := g.output.WriteFile(outputPath, data, mode); err != nil {
 		// TODO wrap custom typed error
 		return fmt.Errorf("write generated file to %q: %w", outputPath, err)
 	}
 
+	if isExplicitMode {
+		verbose.Printf("chmod %o %s", mode, outputPath)
+		if err := g.output.Chmod(outputPath, mode); err != nil {
+			return fmt.Errorf("set mode of %q: %w", outputPath, err)
+		}
+	}
+
+	if !isWritten {
+		g.origins[outputPath] = fileOrigin{template: g.templateIndex, name: g.config.Manifest.Name}
+		g.files = append(g.files, GeneratedFile{
+			Path:     outputPath,
+			Template: g.config.Manifest.Name,
+			Size:     len(data),
+			Mode:     mode,
+		})
+		return nil
+	}
+
+	for i := range g.files {
+		if g.files[i].Path == outputPath {
+			g.files[i].Size = len(data)
+			g.files[i].Mode = mode
+		}
+	}
+
 	return nil
 }
 
+// Files returns files written by generator.
+func (g *Generator) Files() []GeneratedFile {
+	return g.files
+}
+
+// Commands returns rendered shell scripts executed by generator, or planned to be executed in dry run.
+func (g *Generator) Commands() []string {
+	return g.commands
+}
+
+// Steps returns steps executed by generator, or planned to be executed in dry run.
+// Skipped optional steps and steps with false condition are not included.
+func (g *Generator) Steps() []ExecutedStep {
+	return g.steps
+}
+
 // RenderOutputPath renders output path for passed file from raw output path template.
 func (g *Generator) RenderOutputPath(f manifest.File) (string, error) {
 	verbose.Printf("parsing output path template %q", f.Output)
-	t, err := template.New(f.Output).Parse(f.Output)
+	t, err := f.Delims.Or(g.manifestDelims()).NewTemplate(f.Output).Parse(f.Output)
 	if err != nil {
 		// TODO wrap custom typed error
 		return "", fmt.Errorf("parse output path template %q: %w", f.Output, err)
@@ -169,10 +634,35 @@ func (g *Generator) RenderOutputPath(f manifest.File) (string, error) {
 	return outputPath.String(), nil
 }
 
+// evaluateWhen renders when expression against config. Empty expression is always true.
+func (g *Generator) evaluateWhen(when string, delims manifest.Delims) (bool, error) {
+	if strings.TrimSpace(when) == "" {
+		return true, nil
+	}
+
+	verbose.Printf("evaluating condition %q", when)
+	t, err := delims.NewTemplate("when").Parse(when)
+	if err != nil {
+		return false, fmt.Errorf("parse when expression %q: %w", when, err)
+	}
+
+	var rendered strings.Builder
+	if err := t.Execute(&rendered, g.config); err != nil {
+		return false, fmt.Errorf("evaluate when expression %q: %w", when, err)
+	}
+
+	return manifest.IsTruthy(rendered.String()), nil
+}
+
 // RunShell renders passed raw shell script template into actual shell script and then executes it.
-func (g *Generator) RunShell(rawSh string) error {
+// Shell and processes started by it are killed if ctx is done.
+func (g *Generator) RunShell(ctx context.Context, rawSh string) error {
+	return g.runShell(ctx, rawSh, g.manifestDelims())
+}
+
+func (g *Generator) runShell(ctx context.Context, rawSh string, delims manifest.Delims) error {
 	verbose.Printf("parsing shell script template %q", rawSh)
-	t, err := template.New("sh").Parse(rawSh)
+	t, err := delims.NewTemplate("sh").Parse(rawSh)
 	if err != nil {
 		// TODO wrap custom typed error (if possible)
 		return fmt.Errorf("parse shell script template: %w", err)
@@ -184,8 +674,29 @@ func (g *Generator) RunShell(rawSh string) error {
 		return fmt.Errorf("render shell script: %w", err)
 	}
 
-	verbose.Printf("executing shell script %q", sh.String())
-	output, err := exec.Command("sh", "-c", sh.String()).CombinedOutput()
+	g.commands = append(g.commands, sh.String())
+
+	if g.config != nil && g.config.DryRun {
+		verbose.Printf("dry run, shell script %q is not executed", sh.String())
+		return nil
+	}
+
+	out, ok := g.output.(dirOutput)
+	if !ok {
+		return fmt.Errorf("output %T is not located on disk, unable to execute shell script", g.output)
+	}
+
+	if j, ok := g.output.(*journal); ok {
+		if err := j.beforeShell(ctx); err != nil {
+			return err
+		}
+	}
+
+	verbose.Printf("executing shell script %q in %q", sh.String(), out.Dir())
+	cmd := exec.Command("sh", "-c", sh.String())
+	cmd.Dir = out.Dir()
+
+	output, err := runCommand(ctx, cmd)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, string(output))
 		// TODO wrap custom typed error (if possible)
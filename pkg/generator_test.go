@@ -1,13 +1,17 @@
 package projector_test
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/assert"
@@ -21,7 +25,7 @@ var embeddedTestData embed.FS
 
 type noOpProvider struct{}
 
-func (*noOpProvider) Get(filename string) ([]byte, error) { return nil, nil }
+func (*noOpProvider) Get(_ context.Context, filename string) ([]byte, error) { return nil, nil }
 
 func TestMain(m *testing.M) {
 	exitCode := m.Run()
@@ -57,6 +61,33 @@ func TestGenerator_RenderOutputPath(t *testing.T) {
 			},
 			expected: "src/core/the-best-app-loader.js",
 		},
+		{
+			name:    "output path template with variables",
+			isValid: true,
+			cfg: &projector.Config{
+				ProjectName:      "the-best-app",
+				WorkingDirectory: "/home/user/dev/the-best-app",
+				Vars:             map[string]interface{}{"db": "postgres"},
+			},
+			file: manifest.File{
+				Path:   "migration.sql",
+				Output: "migrations/{{ .Vars.db }}/0001_init.sql",
+			},
+			expected: "migrations/postgres/0001_init.sql",
+		},
+		{
+			name:    "output path template with functions",
+			isValid: true,
+			cfg: &projector.Config{
+				ProjectName:      "the-best-app",
+				WorkingDirectory: "/home/user/dev/the-best-app",
+			},
+			file: manifest.File{
+				Path:   "handler.go.tpl",
+				Output: "internal/{{ snake .ProjectName }}/{{ plural \"handler\" }}.go",
+			},
+			expected: "internal/the_best_app/handlers.go",
+		},
 		{
 			name:    "invalid output path template syntax",
 			isValid: false,
@@ -131,7 +162,7 @@ func TestGenerator_ExtractTemplateFrom(t *testing.T) {
 				generator = projector.NewGenerator(cfg, provider)
 			)
 
-			tpl, err := generator.ExtractTemplateFrom(tc.filename)
+			tpl, err := generator.ExtractTemplateFrom(context.Background(), tc.filename)
 
 			if tc.isValid {
 				require.NoError(t, err)
@@ -175,7 +206,7 @@ func TestGenerator_RunShell(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			generator := projector.NewGenerator(nil, nil)
 
-			err := generator.RunShell(tc.shellScript)
+			err := generator.RunShell(context.Background(), tc.shellScript)
 
 			if tc.isValid {
 				require.NoError(t, err)
@@ -187,13 +218,27 @@ func TestGenerator_RunShell(t *testing.T) {
 	}
 }
 
+func TestGenerator_RunShell_Interrupted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+
+	// background sleep keeps output of shell open, so it must be killed together with shell
+	err := projector.NewGenerator(nil, nil).RunShell(ctx, "sleep 10 & sleep 10")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Less(t, time.Since(started), 5*time.Second)
+}
+
 func TestGenerator_ProcessFiles(t *testing.T) {
 	type testCase struct {
-		name               string
-		isValid            bool
-		files              []manifest.File
-		config             *projector.Config
-		expectedFilesExist []string
+		name                   string
+		isValid                bool
+		files                  []manifest.File
+		config                 *projector.Config
+		expectedFilesExist     []string
+		expectedFilesDontExist []string
 	}
 
 	testCases := []testCase{
@@ -221,6 +266,111 @@ func TestGenerator_ProcessFiles(t *testing.T) {
 				"testdata/output/my awesome app/go.mod",
 			},
 		},
+		{
+			name:    "glob pattern with excludes and trimmed suffix",
+			isValid: true,
+			files: []manifest.File{
+				{
+					Path:       "src/**/*.tpl",
+					Output:     "testdata/output/{{ .ProjectName }}-glob",
+					Exclude:    []string{"**/*_test.go.tpl"},
+					TrimSuffix: ".tpl",
+				},
+			},
+			config: &projector.Config{
+				ProjectName: "glob-app",
+				Manifest:    &manifest.Manifest{Name: "glob-app"},
+			},
+			expectedFilesExist: []string{
+				"testdata/output/glob-app-glob/main.go",
+				"testdata/output/glob-app-glob/handlers/user.go",
+			},
+			expectedFilesDontExist: []string{
+				"testdata/output/glob-app-glob/handlers/user_test.go",
+				"testdata/output/glob-app-glob/README.md",
+			},
+		},
+		{
+			name:    "directory",
+			isValid: true,
+			files: []manifest.File{
+				{
+					Path:   "src",
+					Output: "testdata/output/{{ .ProjectName }}-dir/src",
+				},
+			},
+			config: &projector.Config{
+				ProjectName: "glob-app",
+				Manifest:    &manifest.Manifest{Name: "glob-app"},
+			},
+			expectedFilesExist: []string{
+				"testdata/output/glob-app-dir/src/main.go.tpl",
+				"testdata/output/glob-app-dir/src/handlers/user.go.tpl",
+				"testdata/output/glob-app-dir/src/handlers/user_test.go.tpl",
+			},
+		},
+		{
+			name:    "glob pattern matching no files",
+			isValid: false,
+			files: []manifest.File{
+				{
+					Path:   "src/**/*.md",
+					Output: "testdata/output/{{ .ProjectName }}-no-match",
+				},
+			},
+			config: &projector.Config{
+				ProjectName: "glob-app",
+				Manifest:    &manifest.Manifest{Name: "glob-app"},
+			},
+		},
+		{
+			name:    "directory with all files excluded",
+			isValid: false,
+			files: []manifest.File{
+				{
+					Path:    "src",
+					Output:  "testdata/output/{{ .ProjectName }}-excluded",
+					Exclude: []string{"**"},
+				},
+			},
+			config: &projector.Config{
+				ProjectName: "glob-app",
+				Manifest:    &manifest.Manifest{Name: "glob-app"},
+			},
+		},
+		{
+			name:    "files with false when expression are skipped",
+			isValid: true,
+			files: []manifest.File{
+				{
+					Path:   "main.go.tpl",
+					Output: "testdata/output/{{ .ProjectName }}-when/main.go",
+					When:   `{{ eq .Vars.db "none" }}`,
+				},
+				{
+					Path:   "go.mod.tpl",
+					Output: "testdata/output/{{ .ProjectName }}-when/go.mod",
+					When:   `{{ eq .Vars.db "postgres" }}`,
+				},
+				{
+					Path:   "Makefile",
+					Output: "testdata/output/{{ .ProjectName }}-when/Makefile",
+					When:   "{{ .Vars.migrations }}",
+				},
+			},
+			config: &projector.Config{
+				ProjectName: "awesome-app",
+				Manifest:    &manifest.Manifest{Name: "awesome-app"},
+				Vars:        map[string]interface{}{"db": "postgres"},
+			},
+			expectedFilesExist: []string{
+				"testdata/output/awesome-app-when/go.mod",
+			},
+			expectedFilesDontExist: []string{
+				"testdata/output/awesome-app-when/main.go",
+				"testdata/output/awesome-app-when/Makefile",
+			},
+		},
 		{
 			name:    "one of input files does not exist",
 			isValid: false,
@@ -275,7 +425,7 @@ func TestGenerator_ProcessFiles(t *testing.T) {
 				generator = projector.NewGenerator(tc.config, p)
 			)
 
-			err := generator.ProcessFiles(tc.files)
+			err := generator.ProcessFiles(context.Background(), tc.files)
 
 			if tc.isValid {
 				require.NoError(t, err)
@@ -283,6 +433,10 @@ func TestGenerator_ProcessFiles(t *testing.T) {
 				for _, expected := range tc.expectedFilesExist {
 					assert.FileExists(t, expected)
 				}
+
+				for _, expected := range tc.expectedFilesDontExist {
+					assert.NoFileExists(t, expected)
+				}
 				return
 			}
 
@@ -291,6 +445,118 @@ func TestGenerator_ProcessFiles(t *testing.T) {
 	}
 }
 
+func TestGenerator_ProcessFiles_Modes(t *testing.T) {
+	var (
+		wd        = t.TempDir()
+		generator = projector.NewGenerator(
+			&projector.Config{
+				ProjectName: "app",
+				Manifest:    &manifest.Manifest{Name: "modes"},
+			},
+			manifest.NewRealFSProvider("testdata/"),
+		)
+	)
+
+	err := generator.ProcessFiles(context.Background(), []manifest.File{
+		{Path: "run.sh.tpl", Output: filepath.Join(wd, "bin/run.sh")},
+		{Path: "config.env.tpl", Output: filepath.Join(wd, "config.env")},
+		{Path: "secret.env.tpl", Output: filepath.Join(wd, "secret.env"), Mode: "0600"},
+	})
+	require.NoError(t, err)
+
+	requireModes(t, wd)
+}
+
+func TestGenerator_ProcessFiles_OverwrittenModes(t *testing.T) {
+	wd := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(wd, "bin"), 0o755))
+	for name, mode := range map[string]os.FileMode{
+		"bin/run.sh": 0o644,
+		"config.env": 0o755,
+		"secret.env": 0o666,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(wd, name), []byte("old\n"), mode))
+		require.NoError(t, os.Chmod(filepath.Join(wd, name), mode))
+	}
+
+	generator := projector.NewGenerator(
+		&projector.Config{
+			ProjectName: "app",
+			Manifest:    &manifest.Manifest{Name: "modes"},
+			Overwrite:   manifest.OverwriteAlways,
+		},
+		manifest.NewRealFSProvider("testdata/"),
+	)
+
+	err := generator.ProcessFiles(context.Background(), []manifest.File{
+		{Path: "run.sh.tpl", Output: filepath.Join(wd, "bin/run.sh")},
+		{Path: "config.env.tpl", Output: filepath.Join(wd, "config.env")},
+		{Path: "secret.env.tpl", Output: filepath.Join(wd, "secret.env"), Mode: "0600"},
+	})
+	require.NoError(t, err)
+
+	requireModes(t, wd)
+}
+
+// requireModes checks modes of files generated from "modes" testdata into dir. Umask may only clear bits
+// of derived modes, so it is checked that nothing is group or world writable and only scripts are executable.
+func requireModes(t *testing.T, dir string) {
+	t.Helper()
+
+	for _, name := range []string{"bin", "bin/run.sh", "config.env"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.Zero(t, info.Mode().Perm()&0o022, "%q is writable by others: %s", name, info.Mode())
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "bin/run.sh"))
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode().Perm()&0o100, "executable bit is lost: %s", info.Mode())
+
+	info, err = os.Stat(filepath.Join(dir, "config.env"))
+	require.NoError(t, err)
+	require.Zero(t, info.Mode().Perm()&0o111, "file is executable: %s", info.Mode())
+
+	info, err = os.Stat(filepath.Join(dir, "secret.env"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestGenerator_ProcessFiles_Raw(t *testing.T) {
+	var (
+		wd        = t.TempDir()
+		generator = projector.NewGenerator(
+			&projector.Config{
+				ProjectName: "app",
+				Manifest:    &manifest.Manifest{Name: "raw", Raw: []string{"chart/**"}},
+			},
+			manifest.NewRealFSProvider("testdata/"),
+		)
+	)
+
+	err := generator.ProcessFiles(context.Background(), []manifest.File{
+		{Path: "README.md.tpl", Output: filepath.Join(wd, "README.md")},
+		{Path: "logo.png", Output: filepath.Join(wd, "logo.png")},
+		{Path: ".github/workflow.yml", Output: filepath.Join(wd, ".github/workflow.yml"), Raw: true},
+		{Path: "chart", Output: filepath.Join(wd, "chart")},
+	})
+	require.NoError(t, err)
+
+	readme, err := os.ReadFile(filepath.Join(wd, "README.md"))
+	require.NoError(t, err)
+	require.Equal(t, "# app\n", string(readme))
+
+	// binary, raw and matching raw patterns files are copied byte-for-byte
+	for _, name := range []string{"logo.png", ".github/workflow.yml", "chart/templates/deployment.yaml"} {
+		expected, err := os.ReadFile(filepath.Join("testdata/raw", name))
+		require.NoError(t, err)
+
+		actual, err := os.ReadFile(filepath.Join(wd, name))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, name)
+	}
+}
+
 func TestGenerator_Generate(t *testing.T) {
 	type testCase struct {
 		name          string
@@ -386,6 +652,28 @@ func TestGenerator_Generate(t *testing.T) {
 				OptionalSteps:    []string{"not-existing-step-1", "not-existing-step-2", "not-existing-step-3"},
 			},
 		},
+		{
+			name:    "steps with false when expression are skipped",
+			isValid: true,
+			config: &projector.Config{
+				ProjectName:      "projector-test",
+				ProjectPackage:   "projector-test",
+				ProjectAuthor:    "tomakado",
+				WorkingDirectory: "testdata/output/projector-test-5",
+				Manifest: &manifest.Manifest{
+					Name:   "conditional-template",
+					Author: "tomakado",
+					Steps: []manifest.Step{
+						{
+							Name:  "migrations",
+							Shell: "exit 1",
+							When:  `{{ eq .Vars.db "postgres" }}`,
+						},
+					},
+				},
+				Vars: map[string]interface{}{"db": "none"},
+			},
+		},
 		{
 			name:    "Generate returns error if RenderOutputPath returns error",
 			isValid: false,
@@ -416,14 +704,9 @@ func TestGenerator_Generate(t *testing.T) {
 	for _, testCase := range testCases {
 		tc := testCase
 		t.Run(tc.name, func(t *testing.T) {
-			startWorkingDirectory, err := os.Getwd()
-			if err != nil {
-				require.NoError(t, err)
-			}
-
 			p := manifest.NewEmbedFSProvider(&embeddedTestData, "testdata/embed/")
 
-			err = projector.Generate(tc.config, p)
+			err := projector.Generate(context.Background(), tc.config, p)
 
 			if tc.isValid {
 				require.NoError(t, err)
@@ -431,9 +714,6 @@ func TestGenerator_Generate(t *testing.T) {
 				for _, expectedFile := range tc.expectedFiles {
 					ef := expectedFile
 					t.Run("project folder has expected state", func(t *testing.T) {
-						if err := os.Chdir(startWorkingDirectory); err != nil {
-							require.NoError(t, err)
-						}
 						require.FileExists(t, ef.path)
 
 						content, err := os.ReadFile(ef.path)
@@ -445,9 +725,6 @@ func TestGenerator_Generate(t *testing.T) {
 				for _, expectedFile := range tc.expectedFilesDontExist {
 					ef := expectedFile
 					t.Run("file %q does not exist", func(t *testing.T) {
-						if err := os.Chdir(startWorkingDirectory); err != nil {
-							require.NoError(t, err)
-						}
 						require.NoFileExists(t, ef)
 					})
 				}
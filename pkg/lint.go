@@ -0,0 +1,396 @@
+package projector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tomakado/projector/internal/pkg/glob"
+	"github.com/tomakado/projector/internal/pkg/verbose"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+// Severities of lint issues.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// LintConfig describes template checked by Lint.
+type LintConfig struct {
+	Provider       provider
+	PathToManifest string
+
+	// Disabled contains IDs of rules skipped by Lint.
+	Disabled []string
+
+	// Resolver locates templates extended by manifest by name, see CreateConfig.
+	Resolver manifest.Resolver
+}
+
+// LintIssue is violation of lint rule found in template.
+type LintIssue struct {
+	Rule     string
+	Severity string
+
+	// Position points to manifest field or template file issue is found in.
+	Position manifest.Position
+
+	Message string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", i.Position, i.Severity, i.Message, i.Rule)
+}
+
+// LintRule checks template for common mistakes and violations of best practices.
+type LintRule struct {
+	ID          string
+	Description string
+	Severity    string
+
+	check func(ctx context.Context, l *linter) ([]LintIssue, error)
+}
+
+// LintRules returns all rules run by Lint in order of execution.
+func LintRules() []LintRule {
+	return []LintRule{
+		{
+			ID:          "missing-file",
+			Description: "file path doesn't match any file of template",
+			Severity:    SeverityError,
+			check:       lintMissingFiles,
+		},
+		{
+			ID:          "duplicate-step",
+			Description: "several steps have the same name, so only the first one can be included as optional",
+			Severity:    SeverityError,
+			check:       lintDuplicateSteps,
+		},
+		{
+			ID:          "output-escape",
+			Description: "output path is absolute or points outside of working directory",
+			Severity:    SeverityError,
+			check:       lintOutputEscape,
+		},
+		{
+			ID:          "undefined-field",
+			Description: "template uses field or variable missing in generation context, e.g. .ProjctName",
+			Severity:    SeverityError,
+			check:       lintUndefinedFields,
+		},
+		{
+			ID:          "unused-file",
+			Description: "template file is not used by any step",
+			Severity:    SeverityWarning,
+			check:       lintUnusedFiles,
+		},
+		{
+			ID:          "curl-pipe-shell",
+			Description: "shell script pipes downloaded script into shell, e.g. curl | sh",
+			Severity:    SeverityWarning,
+			check:       lintCurlPipeShell,
+		},
+		{
+			ID:          "missing-description",
+			Description: "manifest or variable has no description",
+			Severity:    SeverityWarning,
+			check:       lintMissingDescriptions,
+		},
+	}
+}
+
+// linter is template checked by lint rules and by static check of template fields.
+type linter struct {
+	provider provider
+	dir      string
+	manifest *manifest.Manifest
+	locator  *manifest.Locator
+}
+
+// Lint loads and validates manifest of template and checks template against lint rules except disabled ones.
+// Issues are sorted by position.
+func Lint(ctx context.Context, cfg LintConfig) ([]LintIssue, error) {
+	rules := LintRules()
+
+	disabled := map[string]struct{}{}
+	for _, id := range cfg.Disabled {
+		disabled[id] = struct{}{}
+	}
+
+	for id := range disabled {
+		if !hasLintRule(rules, id) {
+			return nil, fmt.Errorf("unknown lint rule %q", id)
+		}
+	}
+
+	m, locator, err := manifest.LoadWithLocator(
+		ctx,
+		cfg.Provider,
+		filepath.Join(cfg.PathToManifest, "projector.toml"),
+		cfg.Resolver,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load manifest %q: %w", cfg.PathToManifest, err)
+	}
+
+	l := &linter{provider: cfg.Provider, dir: cfg.PathToManifest, manifest: m, locator: locator}
+
+	var issues []LintIssue
+	for _, rule := range rules {
+		if _, ok := disabled[rule.ID]; ok {
+			verbose.Printf("lint rule %q is disabled, skipping", rule.ID)
+			continue
+		}
+
+		verbose.Printf("running lint rule %q", rule.ID)
+		found, err := rule.check(ctx, l)
+		if err != nil {
+			return nil, fmt.Errorf("lint rule %q: %w", rule.ID, err)
+		}
+
+		for _, issue := range found {
+			issue.Rule, issue.Severity = rule.ID, rule.Severity
+			issues = append(issues, issue)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Position, issues[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Column < b.Column
+	})
+
+	return issues, nil
+}
+
+func hasLintRule(rules []LintRule, id string) bool {
+	for _, rule := range rules {
+		if rule.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// issue makes issue positioned at manifest field.
+func (l *linter) issue(field, format string, args ...interface{}) LintIssue {
+	return LintIssue{Position: l.locator.Locate(field), Message: fmt.Sprintf(format, args...)}
+}
+
+// stepSource returns location of template files of step.
+func (l *linter) stepSource(step manifest.Step) templateSource {
+	return templateSource{provider: l.provider, dir: l.dir}.of(step)
+}
+
+// templateFiles returns paths of template files matched by file of step relative to directory of step files.
+func (l *linter) templateFiles(ctx context.Context, step manifest.Step, file manifest.File) ([]string, error) {
+	var (
+		src          = l.stepSource(step)
+		ls, isLister = src.provider.(lister)
+		isGlob       = glob.HasMeta(file.Path)
+		pattern      = path.Clean(filepath.ToSlash(file.Path))
+		root         = pattern
+	)
+
+	if isGlob {
+		if !isLister {
+			return nil, fmt.Errorf("expand %q: provider %T is unable to list files", file.Path, src.provider)
+		}
+		root = glob.Base(pattern)
+	}
+
+	if isLister {
+		names, err := ls.List(ctx, filepath.Join(src.dir, root))
+		switch {
+		case err == nil:
+			var matched []string
+			for _, name := range names {
+				if p := path.Join(root, name); (!isGlob || glob.Match(pattern, p)) && !isExcluded(file.Exclude, name) {
+					matched = append(matched, p)
+				}
+			}
+			return matched, nil
+		case isGlob && errors.Is(err, manifest.ErrFileNotFound):
+			return nil, nil
+		case !errors.Is(err, manifest.ErrNotDirectory) && !errors.Is(err, manifest.ErrFileNotFound):
+			return nil, fmt.Errorf("list files in %q: %w", root, err)
+		}
+	}
+
+	if _, err := src.provider.Get(ctx, filepath.Join(src.dir, pattern)); err != nil {
+		if errors.Is(err, manifest.ErrFileNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return []string{pattern}, nil
+}
+
+func lintMissingFiles(ctx context.Context, l *linter) ([]LintIssue, error) {
+	var issues []LintIssue
+	for i, step := range l.manifest.Steps {
+		for j, file := range step.Files {
+			paths, err := l.templateFiles(ctx, step, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(paths) == 0 {
+				issues = append(issues, l.issue(
+					fmt.Sprintf("steps[%d].files[%d].path", i, j),
+					"path %q doesn't match any template file", file.Path,
+				))
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+func lintDuplicateSteps(_ context.Context, l *linter) ([]LintIssue, error) {
+	var (
+		issues []LintIssue
+		seen   = map[string]int{}
+	)
+
+	for i, step := range l.manifest.Steps {
+		first, ok := seen[step.Name]
+		if !ok {
+			seen[step.Name] = i
+			continue
+		}
+
+		issues = append(issues, l.issue(
+			fmt.Sprintf("steps[%d].name", i),
+			"step name %q is already used by step #%d", step.Name, first+1,
+		))
+	}
+
+	return issues, nil
+}
+
+func lintOutputEscape(_ context.Context, l *linter) ([]LintIssue, error) {
+	var issues []LintIssue
+	for i, step := range l.manifest.Steps {
+		for j, file := range step.Files {
+			output := path.Clean(filepath.ToSlash(file.Output))
+			if file.Output == "" || (!path.IsAbs(output) && output != ".." && !strings.HasPrefix(output, "../")) {
+				continue
+			}
+
+			issues = append(issues, l.issue(
+				fmt.Sprintf("steps[%d].files[%d].output", i, j),
+				"output path %q is outside of working directory", file.Output,
+			))
+		}
+	}
+
+	return issues, nil
+}
+
+func lintUndefinedFields(ctx context.Context, l *linter) ([]LintIssue, error) {
+	errs, err := l.fieldErrors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]LintIssue, 0, len(errs))
+	for _, e := range errs {
+		issues = append(issues, LintIssue{Position: e.Position, Message: e.Message})
+	}
+
+	return issues, nil
+}
+
+func lintUnusedFiles(ctx context.Context, l *linter) ([]LintIssue, error) {
+	ls, ok := l.provider.(lister)
+	if !ok {
+		verbose.Printf("provider %T is unable to list files, skipping", l.provider)
+		return nil, nil
+	}
+
+	names, err := ls.List(ctx, l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("list files in %q: %w", l.dir, err)
+	}
+
+	used := map[string]struct{}{"projector.toml": {}}
+	for _, step := range l.manifest.Steps {
+		// files of inherited steps are located in directory of extended template
+		if step.Dir != "" {
+			continue
+		}
+
+		for _, file := range step.Files {
+			paths, err := l.templateFiles(ctx, step, file)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, p := range paths {
+				used[p] = struct{}{}
+			}
+		}
+	}
+
+	var issues []LintIssue
+	for _, name := range names {
+		if _, ok := used[name]; ok {
+			continue
+		}
+
+		issues = append(issues, LintIssue{
+			Position: manifest.Position{Filename: filepath.Join(l.dir, name)},
+			Message:  fmt.Sprintf("template file %q is not used by any step", name),
+		})
+	}
+
+	return issues, nil
+}
+
+// curlPipeShell matches download of script piped directly into shell, e.g. "curl -sSL https://x.io | sudo bash".
+var curlPipeShell = regexp.MustCompile(`\b(?:curl|wget)\b[^|\n]*\|\s*(?:sudo\s+)?(?:ba|z|da|k)?sh\b`)
+
+func lintCurlPipeShell(_ context.Context, l *linter) ([]LintIssue, error) {
+	var issues []LintIssue
+	for i, step := range l.manifest.Steps {
+		if curlPipeShell.MatchString(step.Shell) {
+			issues = append(issues, l.issue(
+				fmt.Sprintf("steps[%d].shell", i),
+				"shell script of step %q pipes downloaded script into shell, download and verify it first", step.Name,
+			))
+		}
+	}
+
+	return issues, nil
+}
+
+func lintMissingDescriptions(_ context.Context, l *linter) ([]LintIssue, error) {
+	var issues []LintIssue
+	if strings.TrimSpace(l.manifest.Description) == "" {
+		issues = append(issues, l.issue("description", "manifest has no description"))
+	}
+
+	for i, v := range l.manifest.Variables {
+		if strings.TrimSpace(v.Description) == "" {
+			issues = append(issues, l.issue(fmt.Sprintf("variables[%d]", i), "variable %q has no description", v.Name))
+		}
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,105 @@
+package projector_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+func TestLint(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	issue := func(rule, severity, filename string, line, column int, message string) projector.LintIssue {
+		return projector.LintIssue{
+			Rule:     rule,
+			Severity: severity,
+			Position: manifest.Position{Filename: filename, Line: line, Column: column},
+			Message:  message,
+		}
+	}
+
+	var (
+		p            = manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata"))
+		manifestFile = "lint/projector.toml"
+		allIssues    = []projector.LintIssue{
+			issue("undefined-field", projector.SeverityError, "lint/main.go.tpl", 6, 24, `undefined field ".Manifest.Versoin"`),
+			issue("undefined-field", projector.SeverityError, "lint/main.go.tpl", 8, 57, `undefined variable "dbb"`),
+			issue("unused-file", projector.SeverityWarning, "lint/notes.txt", 0, 0, `template file "notes.txt" is not used by any step`),
+			issue("missing-description", projector.SeverityWarning, manifestFile, 0, 0, "manifest has no description"),
+			issue("missing-description", projector.SeverityWarning, manifestFile, 9, 1, `variable "port" has no description`),
+			issue(
+				"curl-pipe-shell", projector.SeverityWarning, manifestFile, 16, 1,
+				`shell script of step "init" pipes downloaded script into shell, download and verify it first`,
+			),
+			issue("undefined-field", projector.SeverityError, manifestFile, 23, 1, `undefined variable "docker"`),
+			issue("undefined-field", projector.SeverityError, manifestFile, 30, 2, `undefined field ".ProjctName"`),
+			issue("missing-file", projector.SeverityError, manifestFile, 33, 2, `path "missing.txt" doesn't match any template file`),
+			issue("output-escape", projector.SeverityError, manifestFile, 34, 2, `output path "../missing.txt" is outside of working directory`),
+			issue("duplicate-step", projector.SeverityError, manifestFile, 37, 1, `step name "init" is already used by step #1`),
+		}
+	)
+
+	type testCase struct {
+		name           string
+		path           string
+		disabled       []string
+		isValid        bool
+		expectedIssues []projector.LintIssue
+	}
+
+	testCases := []testCase{
+		{
+			name:           "all rules are run",
+			path:           "lint",
+			isValid:        true,
+			expectedIssues: allIssues,
+		},
+		{
+			name:     "disabled rules are skipped",
+			path:     "lint",
+			disabled: []string{"undefined-field", "missing-description", "unused-file", "curl-pipe-shell"},
+			isValid:  true,
+			expectedIssues: []projector.LintIssue{
+				allIssues[8],
+				allIssues[9],
+				allIssues[10],
+			},
+		},
+		{
+			name:     "unknown rule can't be disabled",
+			path:     "lint",
+			disabled: []string{"no-such-rule"},
+			isValid:  false,
+		},
+		{
+			name:    "invalid manifest",
+			path:    "lint/templates",
+			isValid: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			issues, err := projector.Lint(context.Background(), projector.LintConfig{
+				Provider:       p,
+				PathToManifest: tc.path,
+				Disabled:       tc.disabled,
+			})
+
+			if !tc.isValid {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedIssues, issues)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"text/template"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/tomakado/projector/internal/pkg/funcs"
+)
+
+// Delims are left and right delimiters of template actions, e.g. ["[[", "]]"]. Empty Delims mean
+// default "{{" and "}}". Delims set for manifest are inherited by steps and delims of step are
+// inherited by its files.
+type Delims []string
+
+func (d Delims) Validate() error {
+	if len(d) == 0 {
+		return nil
+	}
+
+	if len(d) != 2 || d[0] == "" || d[1] == "" {
+		return errors.New("must contain non-empty left and right delimiters")
+	}
+
+	return nil
+}
+
+// Or returns d if it is set, parent otherwise.
+func (d Delims) Or(parent Delims) Delims {
+	if len(d) == 0 {
+		return parent
+	}
+
+	return d
+}
+
+// NewTemplate returns template with delimiters d and functions available in templates.
+func (d Delims) NewTemplate(name string) *template.Template {
+	t := template.New(name).Funcs(funcs.Map())
+	if len(d) == 2 {
+		t = t.Delims(d[0], d[1])
+	}
+
+	return t
+}
+
+// templateSyntax returns rule that test-parses value as template with delimiters d.
+func templateSyntax(d Delims, what string) validation.RuleFunc {
+	return func(v interface{}) error {
+		text := v.(string)
+		if text == "" {
+			return nil
+		}
+
+		if _, err := d.NewTemplate(what).Parse(text); err != nil {
+			return fmt.Errorf("parse %s: %w", what, err)
+		}
+
+		return nil
+	}
+}
@@ -1,12 +1,15 @@
 package manifest
 
 import (
+	"context"
 	"embed"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/tomakado/projector/internal/pkg/verbose"
 )
@@ -27,9 +30,13 @@ func NewEmbedFSProvider(fs *embed.FS, root string) *EmbedFSProvider {
 	}
 }
 
-func (e *EmbedFSProvider) Get(filename string) ([]byte, error) {
+func (e *EmbedFSProvider) Get(ctx context.Context, filename string) ([]byte, error) {
 	verbose.Printf("[EmbedFSProvider] reading %q in %q", filename, e.root)
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	f, err := e.fs.Open(filepath.Join(e.root, filename))
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
@@ -46,3 +53,44 @@ func (e *EmbedFSProvider) Get(filename string) ([]byte, error) {
 
 	return bts, nil
 }
+
+// List returns paths of all files inside dir (recursively) relative to dir.
+func (e *EmbedFSProvider) List(ctx context.Context, dir string) ([]string, error) {
+	verbose.Printf("[EmbedFSProvider] listing %q in %q", dir, e.root)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	root := path.Join(e.root, filepath.ToSlash(dir))
+
+	info, err := fs.Stat(e.fs, root)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("stat %q: %w", dir, ErrFileNotFound)
+		}
+		return nil, fmt.Errorf("stat %q: %w", dir, err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("list %q: %w", dir, ErrNotDirectory)
+	}
+
+	var files []string
+	err = fs.WalkDir(e.fs, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			files = append(files, strings.TrimPrefix(p, root+"/"))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list %q: %w", dir, err)
+	}
+
+	return files, nil
+}
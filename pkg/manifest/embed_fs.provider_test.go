@@ -1,6 +1,7 @@
 package manifest_test
 
 import (
+	"context"
 	"embed"
 	"errors"
 	"testing"
@@ -38,7 +39,7 @@ func TestEmbedFSProvider_Get(t *testing.T) {
 		tc := testCase
 		t.Run(tc.name, func(t *testing.T) {
 			p := manifest.NewEmbedFSProvider(&embeddedTestData, "testdata/embed/")
-			bts, err := p.Get(tc.filename)
+			bts, err := p.Get(context.Background(), tc.filename)
 
 			if tc.isValid {
 				require.NoError(t, err)
@@ -52,3 +53,34 @@ func TestEmbedFSProvider_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestEmbedFSProvider_List(t *testing.T) {
+	p := manifest.NewEmbedFSProvider(&embeddedTestData, "testdata/embed/")
+
+	t.Run("directory is listed recursively", func(t *testing.T) {
+		files, err := p.List(context.Background(), "go")
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			[]string{
+				"hello-world/projector.toml",
+				"hello-world/projector_invalid.toml",
+				"hello-world/projector_invalid_fields.toml",
+				"hello-world/projector_invalid_syntax.toml",
+			},
+			files,
+		)
+	})
+
+	t.Run("file is not a directory", func(t *testing.T) {
+		files, err := p.List(context.Background(), "hello.txt")
+		require.True(t, errors.Is(err, manifest.ErrNotDirectory))
+		require.Nil(t, files)
+	})
+
+	t.Run("directory does not exist", func(t *testing.T) {
+		files, err := p.List(context.Background(), "python")
+		require.True(t, errors.Is(err, manifest.ErrFileNotFound))
+		require.Nil(t, files)
+	})
+}
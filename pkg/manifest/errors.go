@@ -1,6 +1,15 @@
 package manifest
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hashicorp/go-multierror"
+)
 
 var (
 	// ErrFileNotFound is returned when provider is unable to locate file.
@@ -8,4 +17,214 @@ var (
 
 	// ErrPermissionDenied is returned when provider doesn't have permissions to open file.
 	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrNotDirectory is returned when provider is asked to list files of something that is not a directory.
+	ErrNotDirectory = errors.New("not a directory")
+
+	// ErrExtendsCycle is returned when manifest directly or indirectly extends itself.
+	ErrExtendsCycle = errors.New("manifest extends itself")
 )
+
+// Position is location in manifest source. Line and Column start at 1, zero Line means
+// that position inside file is unknown.
+type Position struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.Filename
+	}
+
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+// ValidationError describes invalid or malformed part of manifest. Load and Manifest.Validate return
+// multierror of validation errors, use ValidationErrors to extract them.
+type ValidationError struct {
+	// Position points to invalid field, or to table containing field if it is missing.
+	// It is set only for manifests read by Load.
+	Position Position
+
+	// Field is path to invalid field in TOML notation, e.g. "steps[1].files[0].output".
+	// It is empty for TOML syntax errors.
+	Field string
+
+	// Step is index of step in manifest and File is index of file in step, both start at 0.
+	// They are -1 if error is not related to step or file.
+	Step int
+	File int
+
+	Message string
+
+	// Suggestion is hint on how to fix error, it may be empty.
+	Suggestion string
+
+	// Err is underlying error, it may be nil.
+	Err error
+
+	// stepName is name of step with index Step.
+	stepName string
+
+	// variable is index of variable in manifest or -1.
+	variable int
+}
+
+// Error formats error compiler-style, e.g. `projector.toml:14:3: step "build": file #2: output is required`.
+// Step number is used if step has no name, step and file numbers in message start at 1.
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	if pos := e.Position.String(); pos != "" {
+		b.WriteString(pos + ": ")
+	}
+
+	switch {
+	case e.stepName != "":
+		fmt.Fprintf(&b, "step %q: ", e.stepName)
+	case e.Step >= 0:
+		fmt.Fprintf(&b, "step #%d: ", e.Step+1)
+	}
+
+	if e.File >= 0 {
+		fmt.Fprintf(&b, "file #%d: ", e.File+1)
+	}
+
+	b.WriteString(e.Message)
+
+	if e.Suggestion != "" {
+		b.WriteString(" (" + e.Suggestion + ")")
+	}
+
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors returns validation errors contained in err.
+func ValidationErrors(err error) []*ValidationError {
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		var result []*ValidationError
+		for _, e := range merr.Errors {
+			result = append(result, ValidationErrors(e)...)
+		}
+		return result
+	}
+
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return []*ValidationError{verr}
+	}
+
+	return nil
+}
+
+// newValidationError makes error of field, leaf of field path is used as subject of message.
+func newValidationError(field string, err error) *ValidationError {
+	subject := field
+	if i := strings.LastIndex(subject, "."); i >= 0 {
+		subject = subject[i+1:]
+	}
+
+	var (
+		message = fmt.Sprintf("%s: %s", subject, err)
+		verr    validation.Error
+	)
+
+	if subject == "" {
+		message = err.Error()
+	}
+
+	if errors.As(err, &verr) {
+		message = fmt.Sprintf("%s %s", subject, verr.Error())
+		if verr.Code() == validation.ErrRequired.Code() {
+			message = subject + " is required"
+		}
+	}
+
+	return &ValidationError{Field: field, Step: -1, File: -1, variable: -1, Message: message, Err: err}
+}
+
+// fieldErrors converts error returned by validation.ValidateStruct for structure s into validation errors.
+// Names of fields are replaced with their TOML keys.
+func fieldErrors(s interface{}, err error) []*ValidationError {
+	var fields validation.Errors
+	if !errors.As(err, &fields) {
+		return []*ValidationError{newValidationError("", err)}
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []*ValidationError
+	for _, name := range names {
+		key := tomlKey(s, name)
+
+		var items validation.Errors
+		if !errors.As(fields[name], &items) {
+			result = append(result, newValidationError(key, fields[name]))
+			continue
+		}
+
+		// errors of slice items returned by validation.Each are keyed by index
+		indexes := make([]string, 0, len(items))
+		for index := range items {
+			indexes = append(indexes, index)
+		}
+		sort.Strings(indexes)
+
+		for _, index := range indexes {
+			e := newValidationError(fmt.Sprintf("%s[%s]", key, index), items[index])
+			e.Message = fmt.Sprintf("%s: %s", key, items[index])
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+func tomlKey(s interface{}, field string) string {
+	t := reflect.TypeOf(s)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if f, ok := t.FieldByName(field); ok {
+		if key := strings.Split(f.Tag.Get("toml"), ",")[0]; key != "" {
+			return key
+		}
+	}
+
+	return strings.ToLower(field)
+}
+
+// nest prefixes field paths of errors with path of structure containing them.
+func nest(prefix string, errs []*ValidationError) []*ValidationError {
+	for _, e := range errs {
+		if e.Field == "" {
+			e.Field = prefix
+			continue
+		}
+
+		e.Field = prefix + "." + e.Field
+	}
+
+	return errs
+}
+
+// joinErrors returns multierror of validation errors or nil if there are none.
+func joinErrors(errs []*ValidationError) error {
+	var result error
+	for _, e := range errs {
+		result = multierror.Append(result, e)
+	}
+
+	return result
+}
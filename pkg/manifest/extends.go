@@ -0,0 +1,146 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// resolveExtends returns provider, origin and path of manifest extended by manifest at path.
+// Relative paths are resolved against directory of extending manifest inside its provider,
+// other values are treated as template names and resolved with resolver if it is set.
+func (l *loader) resolveExtends(
+	ctx context.Context, p Provider, origin, path, extends string,
+) (Provider, string, string, error) {
+	if strings.HasPrefix(extends, "./") || strings.HasPrefix(extends, "../") {
+		return p, origin, filepath.Join(filepath.Dir(path), extends, "projector.toml"), nil
+	}
+
+	if l.resolve == nil {
+		return p, origin, filepath.Join(extends, "projector.toml"), nil
+	}
+
+	baseProvider, dir, err := l.resolve(ctx, extends)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return baseProvider, extends, filepath.Join(dir, "projector.toml"), nil
+}
+
+// merge applies manifest on top of extended base manifest.
+func merge(base, m *Manifest) (*Manifest, error) {
+	merged := *base
+	merged.Extends = m.Extends
+
+	for _, field := range []struct{ dst, src *string }{
+		{&merged.Name, &m.Name},
+		{&merged.Author, &m.Author},
+		{&merged.URL, &m.URL},
+		{&merged.Version, &m.Version},
+		{&merged.Description, &m.Description},
+	} {
+		if *field.src != "" {
+			*field.dst = *field.src
+		}
+	}
+
+	if len(m.Raw) > 0 {
+		merged.Raw = append(append([]string{}, base.Raw...), m.Raw...)
+	}
+
+	merged.Variables = append(Variables{}, base.Variables...)
+	for _, v := range m.Variables {
+		if i := merged.Variables.index(v.Name); i >= 0 {
+			merged.Variables[i] = v
+			continue
+		}
+
+		merged.Variables = append(merged.Variables, v)
+	}
+
+	// Steps of base manifest keep its delimiters, manifest ones are not inherited.
+	merged.Delims = m.Delims
+	merged.Steps = make(Steps, 0, len(base.Steps))
+	for _, step := range base.Steps {
+		step.Delims = step.Delims.Or(base.Delims)
+		merged.Steps = append(merged.Steps, step)
+	}
+	for _, step := range m.Steps {
+		steps, err := merged.Steps.apply(step)
+		if err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.Name, err)
+		}
+
+		merged.Steps = steps
+	}
+
+	return &merged, nil
+}
+
+// apply removes, inserts, replaces or appends passed step according to its before, after and remove fields.
+func (s Steps) apply(step Step) (Steps, error) {
+	if step.Remove {
+		i := s.index(step.Name)
+		if i < 0 {
+			return nil, fmt.Errorf("unable to remove unknown step")
+		}
+
+		return append(s[:i], s[i+1:]...), nil
+	}
+
+	if step.Before != "" && step.After != "" {
+		return nil, fmt.Errorf("before and after are mutually exclusive")
+	}
+
+	anchor, offset := step.Before, 0
+	if step.After != "" {
+		anchor, offset = step.After, 1
+	}
+
+	step.Before, step.After = "", ""
+
+	if anchor == "" {
+		if i := s.index(step.Name); i >= 0 {
+			s[i] = step
+			return s, nil
+		}
+
+		return append(s, step), nil
+	}
+
+	if i := s.index(step.Name); i >= 0 {
+		s = append(s[:i], s[i+1:]...)
+	}
+
+	i := s.index(anchor)
+	if i < 0 {
+		return nil, fmt.Errorf("unknown anchor step %q", anchor)
+	}
+
+	i += offset
+	s = append(s[:i], append(Steps{step}, s[i:]...)...)
+
+	return s, nil
+}
+
+func (s Steps) index(name string) int {
+	for i, step := range s {
+		if step.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (vs Variables) index(name string) int {
+	for i, v := range vs {
+		if v.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,227 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomakado/projector/internal/pkg/verbose"
+)
+
+const gitSourcePrefix = "git+"
+
+// GitSource is location of template inside git repository written in form of
+// git+<url>[//<subdirectory>][@<ref>], e.g. git+https://host/org/repo//go/http@v1.2.0.
+// Ref may be tag, branch or commit. Default branch is used if ref is omitted.
+type GitSource struct {
+	URL    string
+	Subdir string
+	Ref    string
+}
+
+// IsGitSource reports whether passed template name refers to git repository.
+func IsGitSource(s string) bool {
+	return strings.HasPrefix(s, gitSourcePrefix)
+}
+
+// ParseGitSource parses template location in git+<url>[//<subdirectory>][@<ref>] form.
+func ParseGitSource(s string) (GitSource, error) {
+	if !IsGitSource(s) {
+		return GitSource{}, fmt.Errorf("git source %q must start with %q", s, gitSourcePrefix)
+	}
+
+	var (
+		source GitSource
+		rest   = strings.TrimPrefix(s, gitSourcePrefix)
+	)
+
+	pathStart := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		pathStart = i + len("://")
+	}
+
+	// ref may contain slashes (e.g. release/1.0), so it is searched after subdirectory separator
+	// or after host part of url, where user name may be followed by "@" too
+	if i := strings.LastIndex(rest, "@"); i >= 0 && i >= refStart(rest, pathStart) {
+		source.Ref = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	if i := strings.Index(rest[pathStart:], "//"); i >= 0 {
+		source.Subdir = path.Clean(rest[pathStart+i+2:])
+		rest = rest[:pathStart+i]
+	}
+
+	source.URL = rest
+
+	if source.URL == "" {
+		return GitSource{}, fmt.Errorf("git source %q has no repository url", s)
+	}
+
+	// values starting with dash would be taken by git as options
+	if strings.HasPrefix(source.URL, "-") {
+		return GitSource{}, fmt.Errorf("git source %q has repository url starting with dash", s)
+	}
+
+	if strings.HasPrefix(source.Ref, "-") {
+		return GitSource{}, fmt.Errorf("git source %q has ref starting with dash", s)
+	}
+
+	if source.Subdir == "." {
+		source.Subdir = ""
+	}
+
+	if strings.HasPrefix(source.Subdir, "..") || path.IsAbs(source.Subdir) {
+		return GitSource{}, fmt.Errorf("git source %q has subdirectory outside of repository", s)
+	}
+
+	return source, nil
+}
+
+// refStart returns index in rest of git source (without prefix) after which ref may start.
+func refStart(rest string, pathStart int) int {
+	if i := strings.Index(rest[pathStart:], "//"); i >= 0 {
+		return pathStart + i + len("//")
+	}
+
+	if pathStart > 0 {
+		if i := strings.Index(rest[pathStart:], "/"); i >= 0 {
+			return pathStart + i
+		}
+		return len(rest)
+	}
+
+	// scp-like url, e.g. git@github.com:org/repo
+	if i := strings.Index(rest, ":"); i >= 0 && !strings.Contains(rest[:i], "/") {
+		return i + 1
+	}
+
+	return 0
+}
+
+func (s GitSource) String() string {
+	var b strings.Builder
+	b.WriteString(gitSourcePrefix)
+	b.WriteString(s.URL)
+
+	if s.Subdir != "" {
+		b.WriteString("//")
+		b.WriteString(s.Subdir)
+	}
+
+	if s.Ref != "" {
+		b.WriteString("@")
+		b.WriteString(s.Ref)
+	}
+
+	return b.String()
+}
+
+// Clone clones repository into dir and checks out ref if it is set. Git is killed if ctx is done.
+func (s GitSource) Clone(ctx context.Context, dir string) error {
+	verbose.Printf("cloning %q into %q", s.URL, dir)
+	if err := runGit(ctx, "", "clone", "--quiet", "--", s.URL, dir); err != nil {
+		return fmt.Errorf("clone %q: %w", s.URL, err)
+	}
+
+	if s.Ref == "" {
+		return nil
+	}
+
+	verbose.Printf("checking out %q", s.Ref)
+	if err := runGit(ctx, dir, "checkout", "--quiet", s.Ref); err != nil {
+		return fmt.Errorf("checkout %q: %w", s.Ref, err)
+	}
+
+	return nil
+}
+
+// GitRevision returns hash of commit checked out in passed repository directory.
+func GitRevision(ctx context.Context, dir string) (string, error) {
+	output, err := gitOutput(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("resolve revision: %w", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	_, err := gitOutput(ctx, dir, args...)
+	return err
+}
+
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("git %s: %w", args[0], ctx.Err())
+	}
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+
+	return string(output), nil
+}
+
+// GitProvider provides template files from git repository cloned into temporary directory.
+// Call Close to remove the clone when provider is not needed anymore.
+type GitProvider struct {
+	source GitSource
+	dir    string
+	fs     *RealFSProvider
+}
+
+// NewGitProvider parses passed git source and clones repository into temporary directory.
+func NewGitProvider(ctx context.Context, source string) (*GitProvider, error) {
+	s, err := ParseGitSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "projector-git-")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary directory: %w", err)
+	}
+
+	if err := s.Clone(ctx, dir); err != nil {
+		os.RemoveAll(dir) //nolint:errcheck
+		return nil, err
+	}
+
+	verbose.Println("initialized git provider")
+
+	return &GitProvider{
+		source: s,
+		dir:    dir,
+		fs:     NewRealFSProvider(filepath.Join(dir, filepath.FromSlash(s.Subdir))),
+	}, nil
+}
+
+func (g *GitProvider) Get(ctx context.Context, filename string) ([]byte, error) {
+	verbose.Printf("[GitProvider] reading %q in %q", filename, g.source)
+	return g.fs.Get(ctx, filename)
+}
+
+// List returns paths of all files inside dir (recursively) relative to dir.
+func (g *GitProvider) List(ctx context.Context, dir string) ([]string, error) {
+	return g.fs.List(ctx, dir)
+}
+
+// Mode returns permission bits of file as checked out from repository.
+func (g *GitProvider) Mode(ctx context.Context, filename string) (fs.FileMode, error) {
+	return g.fs.Mode(ctx, filename)
+}
+
+// Close removes local clone of repository.
+func (g *GitProvider) Close() error {
+	return os.RemoveAll(g.dir)
+}
@@ -0,0 +1,212 @@
+package manifest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/projector/internal/pkg/gittest"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+func TestParseGitSource(t *testing.T) {
+	type testCase struct {
+		name     string
+		isValid  bool
+		source   string
+		expected manifest.GitSource
+	}
+
+	testCases := []testCase{
+		{
+			name:     "repository url only",
+			isValid:  true,
+			source:   "git+https://github.com/tomakado/templates",
+			expected: manifest.GitSource{URL: "https://github.com/tomakado/templates"},
+		},
+		{
+			name:    "subdirectory and tag",
+			isValid: true,
+			source:  "git+https://github.com/tomakado/templates//go/http@v1.2.0",
+			expected: manifest.GitSource{
+				URL:    "https://github.com/tomakado/templates",
+				Subdir: "go/http",
+				Ref:    "v1.2.0",
+			},
+		},
+		{
+			name:    "ssh url with user",
+			isValid: true,
+			source:  "git+ssh://git@github.com/tomakado/templates.git@main",
+			expected: manifest.GitSource{
+				URL: "ssh://git@github.com/tomakado/templates.git",
+				Ref: "main",
+			},
+		},
+		{
+			name:    "local repository with subdirectory",
+			isValid: true,
+			source:  "git+file:///srv/git/templates.git//go/http",
+			expected: manifest.GitSource{
+				URL:    "file:///srv/git/templates.git",
+				Subdir: "go/http",
+			},
+		},
+		{
+			name:    "branch with slash",
+			isValid: true,
+			source:  "git+https://github.com/tomakado/templates@release/1.0",
+			expected: manifest.GitSource{
+				URL: "https://github.com/tomakado/templates",
+				Ref: "release/1.0",
+			},
+		},
+		{
+			name:    "subdirectory and branch with slash",
+			isValid: true,
+			source:  "git+https://github.com/tomakado/templates//go/http@feature/x",
+			expected: manifest.GitSource{
+				URL:    "https://github.com/tomakado/templates",
+				Subdir: "go/http",
+				Ref:    "feature/x",
+			},
+		},
+		{
+			name:    "ssh url with user and branch with slash",
+			isValid: true,
+			source:  "git+ssh://git@github.com/tomakado/templates.git@feature/x",
+			expected: manifest.GitSource{
+				URL: "ssh://git@github.com/tomakado/templates.git",
+				Ref: "feature/x",
+			},
+		},
+		{
+			name:    "scp-like url with branch with slash",
+			isValid: true,
+			source:  "git+git@github.com:tomakado/templates.git//go/http@release/1.0",
+			expected: manifest.GitSource{
+				URL:    "git@github.com:tomakado/templates.git",
+				Subdir: "go/http",
+				Ref:    "release/1.0",
+			},
+		},
+		{
+			name:     "scp-like url without ref",
+			isValid:  true,
+			source:   "git+git@github.com:tomakado/templates.git",
+			expected: manifest.GitSource{URL: "git@github.com:tomakado/templates.git"},
+		},
+		{
+			name:    "missing prefix",
+			isValid: false,
+			source:  "https://github.com/tomakado/templates",
+		},
+		{
+			name:    "missing url",
+			isValid: false,
+			source:  "git+@v1.0.0",
+		},
+		{
+			name:    "subdirectory outside of repository",
+			isValid: false,
+			source:  "git+https://github.com/tomakado/templates//../etc",
+		},
+		{
+			name:    "url starting with dash",
+			isValid: false,
+			source:  "git+--upload-pack=touch /tmp/pwned",
+		},
+		{
+			name:    "ref starting with dash",
+			isValid: false,
+			source:  "git+https://github.com/tomakado/templates@--orphan=pwned",
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := manifest.ParseGitSource(tc.source)
+
+			if tc.isValid {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, actual)
+				require.Equal(t, tc.source, actual.String())
+				return
+			}
+
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGitProvider_Get(t *testing.T) {
+	gittest.SkipIfNoGit(t)
+
+	repo := gittest.NewBareRepo(t).Bare
+
+	type testCase struct {
+		name            string
+		isValid         bool
+		source          string
+		filename        string
+		expectedContent string
+	}
+
+	testCases := []testCase{
+		{
+			name:            "default branch",
+			isValid:         true,
+			source:          "git+file://" + repo + "//tpl",
+			filename:        "hello.txt",
+			expectedContent: "hello from v2\n",
+		},
+		{
+			name:            "tag",
+			isValid:         true,
+			source:          "git+file://" + repo + "//tpl@v1.0.0",
+			filename:        "hello.txt",
+			expectedContent: "hello from v1\n",
+		},
+		{
+			name:            "branch",
+			isValid:         true,
+			source:          "git+file://" + repo + "@feature",
+			filename:        "tpl/hello.txt",
+			expectedContent: "hello from feature\n",
+		},
+		{
+			name:     "file does not exist",
+			isValid:  false,
+			source:   "git+file://" + repo + "//tpl",
+			filename: "world.txt",
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := manifest.NewGitProvider(context.Background(), tc.source)
+			require.NoError(t, err)
+			defer p.Close() //nolint:errcheck
+
+			bts, err := p.Get(context.Background(), tc.filename)
+
+			if tc.isValid {
+				require.NoError(t, err)
+				require.Equal(t, tc.expectedContent, string(bts))
+				return
+			}
+
+			require.Error(t, err)
+			require.True(t, errors.Is(err, manifest.ErrFileNotFound))
+		})
+	}
+
+	t.Run("unknown ref", func(t *testing.T) {
+		p, err := manifest.NewGitProvider(context.Background(), "git+file://"+repo+"@v9.9.9")
+		require.Error(t, err)
+		require.Nil(t, p)
+	})
+}
@@ -1,45 +1,187 @@
 package manifest
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/tomakado/projector/internal/pkg/verbose"
 )
 
-type provider interface {
-	Get(filename string) ([]byte, error)
+// Provider reads files of templates.
+type Provider interface {
+	Get(ctx context.Context, filename string) ([]byte, error)
 }
 
-func Load(p provider, path string) (*Manifest, error) {
+// Resolver locates template extended by manifest by its name, e.g. builtin or cached one. It returns provider
+// of template files and directory of template inside the provider.
+type Resolver func(ctx context.Context, name string) (Provider, string, error)
+
+// Load reads manifest from provider, resolves chain of extended manifests and validates the result.
+//
+// Manifest with extends field inherits meta, variables and steps from extended (base) manifest.
+// Relative paths in extends field (./ or ../) are resolved against directory of extending manifest,
+// other values are template names resolved with resolve. If resolve is nil, names are resolved
+// inside provider of extending manifest.
+// Meta fields and variables set in manifest override inherited ones. Each own step of manifest
+// either removes base step with the same name (remove = true), is inserted before or after named
+// base step (before/after), replaces base step with the same name, or is appended to the end.
+//
+// Problems of manifest are returned as multierror of *ValidationError with positions in manifest files.
+func Load(ctx context.Context, p Provider, path string, resolve Resolver) (*Manifest, error) {
+	manifest, _, err := LoadWithLocator(ctx, p, path, resolve)
+	return manifest, err
+}
+
+// LoadWithLocator loads manifest like Load does and also returns Locator of its fields in manifest files.
+func LoadWithLocator(ctx context.Context, p Provider, path string, resolve Resolver) (*Manifest, *Locator, error) {
+	l := &loader{resolve: resolve, visited: map[string]struct{}{}}
+
+	manifest, sources, err := l.load(ctx, p, "", path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verbose.Println("validating manifest")
+
+	var errs []*ValidationError
+	for _, src := range sources {
+		for _, e := range src.unknown {
+			if e.stepName != "" {
+				e.Step = manifest.Steps.index(e.stepName)
+			}
+			errs = append(errs, e)
+		}
+	}
+
+	for _, e := range manifest.validationErrors() {
+		locate(e, manifest, sources)
+		errs = append(errs, e)
+	}
+
+	if err := joinErrors(errs); err != nil {
+		return nil, nil, err
+	}
+
+	return manifest, &Locator{manifest: manifest, sources: sources}, nil
+}
+
+// loader loads chain of extended manifests.
+type loader struct {
+	resolve Resolver
+
+	// visited contains manifests already loaded in chain identified by template name they are resolved
+	// from and path, as paths of different providers may be equal.
+	visited map[string]struct{}
+}
+
+// load reads manifest and manifests it extends. Origin is name of template provider is resolved from,
+// it is empty for provider passed to Load. Sources of manifest files are returned starting from
+// the manifest itself.
+func (l *loader) load(ctx context.Context, p Provider, origin, path string) (*Manifest, []*source, error) {
 	verbose.Printf("loading manifest %q", path)
 
-	manifestBytes, err := p.Get(path)
+	key := origin + "\x00" + path
+	if _, ok := l.visited[key]; ok {
+		return nil, nil, fmt.Errorf("manifest %q: %w", path, ErrExtendsCycle)
+	}
+	l.visited[key] = struct{}{}
+
+	manifestBytes, err := p.Get(ctx, path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	manifest, err := parseManifest(manifestBytes)
+	manifest, src, err := parseManifest(path, manifestBytes)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if err := manifest.Validate(); err != nil {
-		return nil, err
+	if manifest.Extends == "" {
+		return manifest, []*source{src}, nil
 	}
 
-	return manifest, nil
+	baseProvider, baseOrigin, basePath, err := l.resolveExtends(ctx, p, origin, path, manifest.Extends)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve extended manifest %q: %w", manifest.Extends, err)
+	}
+	verbose.Printf("manifest %q extends %q", path, basePath)
+
+	base, baseSources, err := l.load(ctx, baseProvider, baseOrigin, basePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load extended manifest %q: %w", manifest.Extends, err)
+	}
+
+	// inherited steps keep location of their files
+	for i := range base.Steps {
+		if base.Steps[i].Dir == "" {
+			base.Steps[i].Dir = filepath.Dir(basePath)
+		}
+
+		if base.Steps[i].Provider == nil && baseOrigin != origin {
+			base.Steps[i].Provider = baseProvider
+		}
+	}
+
+	merged, err := merge(base, manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("extend manifest %q: %w", manifest.Extends, err)
+	}
+
+	return merged, append([]*source{src}, baseSources...), nil
 }
 
-func parseManifest(src []byte) (*Manifest, error) {
+// parseManifest decodes manifest and indexes positions of its keys. Syntax errors are returned
+// as *ValidationError, unknown keys are collected in source.
+func parseManifest(path string, src []byte) (*Manifest, *source, error) {
 	verbose.Println("parsing manifest")
 
 	var manifest *Manifest
-	if err := toml.Unmarshal(src, &manifest); err != nil {
-		// TODO wrap custom typed error
-		verbose.Println("toml.Unmarshal returned error")
-		return nil, fmt.Errorf("parse manifest: %w", err)
+	md, err := toml.Decode(string(src), &manifest)
+	if err != nil {
+		verbose.Println("toml.Decode returned error")
+		return nil, nil, joinErrors([]*ValidationError{syntaxError(path, src, err)})
+	}
+
+	if manifest == nil {
+		manifest = &Manifest{}
+	}
+
+	s := indexSource(path, src, manifest)
+	s.unknown = s.unknownKeys(md.Undecoded(), manifest)
+
+	return manifest, s, nil
+}
+
+var parseErrorPrefix = regexp.MustCompile(`^toml: line \d+( \(last key "[^"]*"\))?: `)
+
+// syntaxError converts error of TOML decoder into validation error positioned in manifest source.
+func syntaxError(path string, src []byte, err error) *ValidationError {
+	e := &ValidationError{
+		Position: Position{Filename: path},
+		Step:     -1,
+		File:     -1,
+		Message:  "invalid TOML: " + strings.TrimPrefix(err.Error(), "toml: "),
+		Err:      err,
+		variable: -1,
+	}
+
+	var perr toml.ParseError
+	if errors.As(err, &perr) {
+		start := perr.Position.Start
+		if start > len(src) {
+			start = len(src)
+		}
+
+		e.Position.Line = perr.Position.Line
+		e.Position.Column = start - bytes.LastIndexByte(src[:start], '\n')
+		e.Message = "invalid TOML: " + parseErrorPrefix.ReplaceAllString(perr.Error(), "")
 	}
 
-	return manifest, nil
+	return e
 }
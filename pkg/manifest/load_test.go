@@ -1,7 +1,10 @@
 package manifest_test
 
 import (
+	"context"
 	"embed"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,6 +57,47 @@ func TestLoad(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "extended manifest is merged",
+			isValid: true,
+			path:    "extends/child/projector.toml",
+			expectedManifest: &manifest.Manifest{
+				Name:        "extends/child",
+				Author:      "tomakado",
+				Version:     "1.1.0",
+				Description: "Base template",
+				Extends:     "../base",
+				Variables: manifest.Variables{
+					{Name: "db", Default: "mysql"},
+					{Name: "port", Type: manifest.VariableTypeInt, Default: int64(8080)},
+				},
+				Steps: manifest.Steps{
+					{Name: "init", Shell: "git init --initial-branch=main"},
+					{Name: "deps", Shell: "go mod tidy"},
+					{Name: "lint", Shell: "golangci-lint run"},
+					{
+						Name:  "bootstrap",
+						Files: []manifest.File{{Path: "main.go.tpl", Output: "main.go"}},
+						Dir:   "extends/base",
+					},
+					{Name: "license", Shell: "echo MIT > LICENSE", Dir: "extends/base"},
+					{
+						Name:  "readme",
+						Files: []manifest.File{{Path: "README.md", Output: "README.md"}},
+					},
+				},
+			},
+		},
+		{
+			name:    "extends cycle",
+			isValid: false,
+			path:    "extends/cycle-a/projector.toml",
+		},
+		{
+			name:    "extending manifest removes unknown step",
+			isValid: false,
+			path:    "extends/broken/projector.toml",
+		},
 		{
 			name:    "file does not exist",
 			isValid: false,
@@ -76,7 +120,7 @@ func TestLoad(t *testing.T) {
 	for _, testCase := range testCases {
 		tc := testCase
 		t.Run(tc.name, func(t *testing.T) {
-			m, err := manifest.Load(p, tc.path)
+			m, err := manifest.Load(context.Background(), p, tc.path, nil)
 
 			if tc.isValid {
 				require.NoError(t, err)
@@ -88,3 +132,168 @@ func TestLoad(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_ResolvedExtends(t *testing.T) {
+	var (
+		embedded = manifest.NewEmbedFSProvider(&embedFS, "testdata/embed/")
+		local    = manifest.NewRealFSProvider("testdata/local")
+		resolved []string
+	)
+
+	resolve := func(_ context.Context, name string) (manifest.Provider, string, error) {
+		resolved = append(resolved, name)
+		if name != "extends/base" {
+			return nil, "", fmt.Errorf("template %q is not found", name)
+		}
+
+		return embedded, name, nil
+	}
+
+	t.Run("local template extends builtin one", func(t *testing.T) {
+		m, err := manifest.Load(context.Background(), local, "child/projector.toml", resolve)
+		require.NoError(t, err)
+		require.Equal(t, []string{"extends/base"}, resolved)
+		require.Equal(t, "local/child", m.Name)
+		require.Equal(t, "Base template", m.Description)
+
+		require.Len(t, m.Steps, 5)
+		for _, step := range m.Steps[:4] {
+			require.Equal(t, "extends/base", step.Dir, step.Name)
+			require.Equal(t, embedded, step.Provider, step.Name)
+		}
+
+		readme := m.Steps[4]
+		require.Equal(t, "readme", readme.Name)
+		require.Empty(t, readme.Dir)
+		require.Nil(t, readme.Provider)
+	})
+
+	t.Run("unresolved template", func(t *testing.T) {
+		_, err := manifest.Load(context.Background(), embedded, "extends/resolved-missing/projector.toml", resolve)
+		require.Error(t, err)
+	})
+
+	t.Run("relative extends are resolved inside provider", func(t *testing.T) {
+		resolved = nil
+
+		m, err := manifest.Load(context.Background(), embedded, "extends/child/projector.toml", resolve)
+		require.NoError(t, err)
+		require.Empty(t, resolved)
+		require.Equal(t, "extends/base", m.Steps[3].Dir)
+		require.Nil(t, m.Steps[3].Provider)
+	})
+}
+
+func TestLoad_ValidationErrors(t *testing.T) {
+	// validationError contains exported fields of manifest.ValidationError except wrapped error.
+	type validationError struct {
+		Position   manifest.Position
+		Field      string
+		Step       int
+		File       int
+		Message    string
+		Suggestion string
+	}
+
+	type testCase struct {
+		name           string
+		path           string
+		expectedErrors []validationError
+	}
+
+	testCases := []testCase{
+		{
+			name: "missing required field",
+			path: "go/hello-world/projector_invalid.toml",
+			expectedErrors: []validationError{
+				{
+					Position: manifest.Position{Filename: "go/hello-world/projector_invalid.toml"},
+					Field:    "steps",
+					Step:     -1,
+					File:     -1,
+					Message:  "steps is required",
+				},
+			},
+		},
+		{
+			name: "invalid syntax",
+			path: "go/hello-world/projector_invalid_syntax.toml",
+			expectedErrors: []validationError{
+				{
+					Position: manifest.Position{Filename: "go/hello-world/projector_invalid_syntax.toml", Line: 1, Column: 22},
+					Step:     -1,
+					File:     -1,
+					Message:  "invalid TOML: expected a top-level item to end with a newline, comment, or EOF, but got '=' instead",
+				},
+			},
+		},
+		{
+			name: "unknown key and missing file output",
+			path: "go/hello-world/projector_invalid_fields.toml",
+			expectedErrors: []validationError{
+				{
+					Position:   manifest.Position{Filename: "go/hello-world/projector_invalid_fields.toml", Line: 10, Column: 2},
+					Field:      "steps[0].files[0].ouptut",
+					Step:       0,
+					File:       0,
+					Message:    `unknown key "ouptut"`,
+					Suggestion: `did you mean "output"?`,
+				},
+				{
+					Position: manifest.Position{Filename: "go/hello-world/projector_invalid_fields.toml", Line: 8, Column: 2},
+					Field:    "steps[0].files[0].output",
+					Step:     0,
+					File:     0,
+					Message:  "output is required",
+				},
+				{
+					Position: manifest.Position{Filename: "go/hello-world/projector_invalid_fields.toml", Line: 18, Column: 2},
+					Field:    "steps[1].files[1].output",
+					Step:     1,
+					File:     1,
+					Message:  "output is required",
+				},
+			},
+		},
+	}
+
+	p := manifest.NewEmbedFSProvider(&embedFS, "testdata/embed/")
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := manifest.Load(context.Background(), p, tc.path, nil)
+			require.Error(t, err)
+
+			var first *manifest.ValidationError
+			require.True(t, errors.As(err, &first))
+
+			actualErrors := make([]validationError, 0, len(tc.expectedErrors))
+			for _, e := range manifest.ValidationErrors(err) {
+				actualErrors = append(actualErrors, validationError{
+					Position:   e.Position,
+					Field:      e.Field,
+					Step:       e.Step,
+					File:       e.File,
+					Message:    e.Message,
+					Suggestion: e.Suggestion,
+				})
+			}
+
+			assert.Equal(t, tc.expectedErrors, actualErrors)
+		})
+	}
+}
+
+func TestValidationError_Error(t *testing.T) {
+	e := manifest.ValidationError{
+		Position:   manifest.Position{Filename: "projector.toml", Line: 14, Column: 3},
+		Field:      "steps[0].files[1].output",
+		Step:       0,
+		File:       1,
+		Message:    "output is required",
+		Suggestion: "set output path",
+	}
+
+	assert.Equal(t, "projector.toml:14:3: step #1: file #2: output is required (set output path)", e.Error())
+}
@@ -1,28 +1,75 @@
 package manifest
 
 import (
+	"errors"
 	"fmt"
-	"text/template"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
-	"github.com/hashicorp/go-multierror"
+	"github.com/tomakado/projector/internal/pkg/glob"
 	"github.com/tomakado/projector/internal/pkg/verbose"
 )
 
+// Policies of resolving conflicts with files already existing at output path.
+const (
+	// OverwriteFail stops generation with error.
+	OverwriteFail = "fail"
+	// OverwriteSkip keeps existing file untouched.
+	OverwriteSkip = "skip"
+	// OverwriteAlways replaces existing file with generated one.
+	OverwriteAlways = "overwrite"
+	// OverwritePrompt asks user how to resolve conflict.
+	OverwritePrompt = "prompt"
+	// OverwriteBackup copies existing file to <name>.bak (or <name>.bak.N if taken) before writing generated one.
+	OverwriteBackup = "backup"
+	// OverwriteMerge appends lines of generated file missing in existing one. Suits list-like files only.
+	OverwriteMerge = "merge"
+)
+
+var fileModePattern = regexp.MustCompile(`^0?[0-7]{3}$`)
+
+// OverwritePolicies lists all supported overwrite policies.
+var OverwritePolicies = []string{
+	OverwriteFail,
+	OverwriteSkip,
+	OverwriteAlways,
+	OverwritePrompt,
+	OverwriteBackup,
+	OverwriteMerge,
+}
+
 // Manifest contains all metadata related to project template and actual steps of project generation.
 type Manifest struct {
-	Name        string `toml:"name"`
-	Author      string `toml:"author"`
-	URL         string `toml:"url,omitempty"`
-	Version     string `toml:"version"`
-	Description string `toml:"description"`
-	Steps       Steps  `toml:"steps"`
+	Name        string    `toml:"name"`
+	Author      string    `toml:"author"`
+	URL         string    `toml:"url,omitempty"`
+	Version     string    `toml:"version"`
+	Description string    `toml:"description"`
+	Variables   Variables `toml:"variables,omitempty"`
+	Steps       Steps     `toml:"steps"`
+
+	// Raw contains glob patterns of template files copied as is, without rendering.
+	Raw []string `toml:"raw,omitempty"`
+
+	// Delims are delimiters of template actions used in all steps and files unless they set their own.
+	Delims Delims `toml:"delims,omitempty"`
+
+	// Extends is name of builtin template or relative path ("./" or "../") to directory of template
+	// this manifest inherits meta, variables and steps from. See Load for inheritance rules.
+	Extends string `toml:"extends,omitempty"`
 }
 
 func (m Manifest) Validate() error {
 	verbose.Println("validating manifest")
-	var result error
+	return joinErrors(m.validationErrors())
+}
+
+func (m Manifest) validationErrors() []*ValidationError {
+	var result []*ValidationError
 
 	if err := validation.ValidateStruct(
 		&m,
@@ -30,18 +77,35 @@ func (m Manifest) Validate() error {
 		validation.Field(&m.Author, validation.Required),
 		validation.Field(&m.URL, is.URL),
 		validation.Field(&m.Version, validation.Required),
+		validation.Field(&m.Raw, validation.Each(validation.By(validatePathPattern))),
+		validation.Field(&m.Delims),
 		validation.Field(
 			&m.Steps,
 			validation.Required,
 			validation.Length(1, 0),
+			// steps are validated one by one below with delimiters inherited from manifest
+			validation.Skip,
 		),
 	); err != nil {
-		result = multierror.Append(result, err)
+		result = append(result, fieldErrors(&m, err)...)
 	}
 
-	for _, step := range m.Steps {
-		if err := step.Validate(); err != nil {
-			result = multierror.Append(result, fmt.Errorf(" Step %q: %w", step.Name, err))
+	result = append(result, m.Variables.validationErrors()...)
+
+	for i, step := range m.Steps {
+		step.Delims = step.Delims.Or(m.Delims)
+
+		errs := step.validationErrors()
+		if step.Remove || step.Before != "" || step.After != "" {
+			e := newValidationError("", errors.New("remove, before and after are allowed only in manifest with extends"))
+			e.Suggestion = "set extends or delete these fields"
+			errs = append(errs, e)
+		}
+
+		for _, e := range nest(fmt.Sprintf("steps[%d]", i), errs) {
+			e.Step = i
+			e.stepName = step.Name
+			result = append(result, e)
 		}
 	}
 
@@ -54,78 +118,175 @@ type Step struct {
 	IsOptional bool   `toml:"optional"`
 	Files      []File `toml:"files"`
 	Shell      string `toml:"shell"`
+
+	// When is template expression evaluated against generation context, step is skipped if it
+	// renders to empty string, "false", "0" or "no". See IsTruthy.
+	When string `toml:"when,omitempty"`
+
+	// Before and After insert step before or after named step of extended manifest.
+	Before string `toml:"before,omitempty"`
+	After  string `toml:"after,omitempty"`
+
+	// Remove deletes step with the same name from extended manifest.
+	Remove bool `toml:"remove,omitempty"`
+
+	// Delims are delimiters of template actions in shell script, condition and files of step.
+	Delims Delims `toml:"delims,omitempty"`
+
+	// Dir is directory of template step is inherited from. It is empty for own steps of manifest,
+	// so their files are located in directory of the manifest itself.
+	Dir string `toml:"-"`
+
+	// Provider is provider of template step is inherited from if it differs from provider of the manifest,
+	// e.g. for local template extending builtin one.
+	Provider Provider `toml:"-"`
 }
 
 func (s Step) Validate() error {
-	// TODO validate text/template syntax in `Shell`` field
-	var result error
+	return joinErrors(s.validationErrors())
+}
+
+func (s Step) validationErrors() []*ValidationError {
+	var result []*ValidationError
 
 	if err := validation.ValidateStruct(
 		&s,
 		validation.Field(&s.Name, validation.Required),
+		validation.Field(&s.Delims),
+		validation.Field(&s.When, validation.By(templateSyntax(s.Delims, "when expression"))),
 	); err != nil {
-		result = multierror.Append(result, err)
+		result = append(result, fieldErrors(&s, err)...)
 	}
 
 	if s.Shell == "" && len(s.Files) == 0 {
-		return fmt.Errorf("either Shell or File must be specified")
+		e := newValidationError("", errors.New("either shell or files must be specified"))
+		e.Suggestion = "add shell script or files to step"
+		result = append(result, e)
 	}
 
 	for i, f := range s.Files {
-		if err := f.Validate(); err != nil {
-			result = multierror.Append(result, fmt.Errorf("  File #%d: %w", (i+1), err))
+		f.Delims = f.Delims.Or(s.Delims)
+		for _, e := range nest(fmt.Sprintf("files[%d]", i), f.validationErrors()) {
+			e.File = i
+			result = append(result, e)
 		}
 	}
 
-	if err := s.validateShellScript(); err != nil {
-		result = multierror.Append(result, fmt.Errorf("  Shell: %w", err))
+	if err := templateSyntax(s.Delims, "shell script template")(s.Shell); err != nil {
+		result = append(result, newValidationError("shell", err))
 	}
 
 	return result
 }
 
-func (s *Step) validateShellScript() error {
-	if s.Shell == "" {
-		return nil
+// File is actually mapping between template file and output file. Also template syntax allowed in Output field.
+// If Path is glob pattern or directory, Output is a root where matched files are put preserving their relative paths.
+type File struct {
+	Path   string `toml:"path"`
+	Output string `toml:"output"`
+
+	// Exclude contains glob patterns of files to skip when Path is glob pattern or directory.
+	Exclude []string `toml:"exclude,omitempty"`
+
+	// TrimSuffix is removed from output file names when Path is glob pattern or directory, e.g. ".tpl".
+	TrimSuffix string `toml:"trim_suffix,omitempty"`
+
+	// When is template expression, file is skipped if it renders to falsy value. See IsTruthy.
+	When string `toml:"when,omitempty"`
+
+	// Overwrite is policy applied if output file already exists. Policy of generation run takes precedence if set.
+	Overwrite string `toml:"overwrite,omitempty"`
+
+	// Raw makes file copied as is, without rendering. Binary files are always copied as is.
+	Raw bool `toml:"raw,omitempty"`
+
+	// Mode is octal permission bits of output file, e.g. "0755". Mode of source file is used if it is empty.
+	Mode string `toml:"mode,omitempty"`
+
+	// Delims are delimiters of template actions in file content, output path and condition.
+	Delims Delims `toml:"delims,omitempty"`
+}
+
+// IsRaw reports whether file at path must be copied without rendering according to raw patterns of manifest.
+func (m Manifest) IsRaw(path string) bool {
+	for _, pattern := range m.Raw {
+		if glob.Match(pattern, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FileMode returns parsed Mode. False is returned if Mode is not set.
+func (f File) FileMode() (fs.FileMode, bool, error) {
+	if f.Mode == "" {
+		return 0, false, nil
 	}
 
-	_, err := template.New(s.Shell).Parse(s.Shell)
+	mode, err := strconv.ParseUint(f.Mode, 8, 32)
 	if err != nil {
-		return fmt.Errorf("parse shell script template: %w", err)
+		return 0, false, fmt.Errorf("parse mode %q: %w", f.Mode, err)
 	}
 
-	return nil
+	return fs.FileMode(mode), true, nil
 }
 
-// File is actually mapping between template file and output file. Also template syntax allowed in Output field.
-type File struct {
-	Path   string `toml:"path"`
-	Output string `toml:"output"`
+func (f File) Validate() error {
+	return joinErrors(f.validationErrors())
 }
 
-func (f File) Validate() error {
-	return validation.ValidateStruct(
+func (f File) validationErrors() []*ValidationError {
+	err := validation.ValidateStruct(
 		&f,
-		validation.Field(&f.Path, validation.Required),
-
-		// TODO validate text/template syntax
+		validation.Field(&f.Path, validation.Required, validation.By(validatePathPattern)),
+		validation.Field(&f.Exclude, validation.Each(validation.By(validatePathPattern))),
+		validation.Field(&f.Delims),
+		validation.Field(&f.When, validation.By(templateSyntax(f.Delims, "when expression"))),
+		validation.Field(&f.Overwrite, validation.By(ValidateOverwritePolicy)),
+		validation.Field(&f.Mode, validation.Match(fileModePattern).Error("must be octal permission bits, e.g. 0644")),
 		validation.Field(
 			&f.Output,
 			validation.Required,
-			validation.By(validateOutputSyntax),
+			validation.By(templateSyntax(f.Delims, "file output path template")),
 		),
 	)
+	if err != nil {
+		return fieldErrors(&f, err)
+	}
+
+	return nil
+}
 
+func validatePathPattern(v interface{}) error {
+	return glob.Validate(v.(string))
 }
 
-func validateOutputSyntax(v interface{}) error {
-	output := v.(string)
-	_, err := template.New(output).Parse(output)
-	if err != nil {
-		return fmt.Errorf("parse file output path template: %w", err)
+// ValidateOverwritePolicy checks that v is one of OverwritePolicies or empty string.
+func ValidateOverwritePolicy(v interface{}) error {
+	policy := v.(string)
+	if policy == "" {
+		return nil
 	}
 
-	return nil
+	for _, p := range OverwritePolicies {
+		if policy == p {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown overwrite policy %q, expected one of: %s", policy, strings.Join(OverwritePolicies, ", "))
+}
+
+// IsTruthy reports whether rendered when expression allows step or file to be generated.
+// Empty string, "false", "0", "no" and "<no value>" (missing map key) are considered false.
+func IsTruthy(rendered string) bool {
+	switch strings.ToLower(strings.TrimSpace(rendered)) {
+	case "", "false", "0", "no", "<no value>":
+		return false
+	}
+
+	return true
 }
 
 type Steps []Step
@@ -39,6 +39,59 @@ func TestFile_Validate(t *testing.T) {
 				Output: "",
 			},
 		},
+		{
+			name:    "path has invalid glob pattern",
+			isValid: false,
+			file: manifest.File{
+				Path:   "src/[a-z.tpl",
+				Output: "src",
+			},
+		},
+		{
+			name:    "exclude has invalid glob pattern",
+			isValid: false,
+			file: manifest.File{
+				Path:    "src/**/*.tpl",
+				Output:  "src",
+				Exclude: []string{"[_test.go"},
+			},
+		},
+		{
+			name:    "valid mode",
+			isValid: true,
+			file: manifest.File{
+				Path:   "run.sh",
+				Output: "run.sh",
+				Mode:   "0755",
+			},
+		},
+		{
+			name:    "mode is not octal",
+			isValid: false,
+			file: manifest.File{
+				Path:   "run.sh",
+				Output: "run.sh",
+				Mode:   "rwxr-xr-x",
+			},
+		},
+		{
+			name:    "unknown overwrite policy",
+			isValid: false,
+			file: manifest.File{
+				Path:      "gitignore",
+				Output:    ".gitignore",
+				Overwrite: "ignore",
+			},
+		},
+		{
+			name:    "when has bad syntax",
+			isValid: false,
+			file: manifest.File{
+				Path:   "migrations",
+				Output: "migrations",
+				When:   "{{ .Vars.db }",
+			},
+		},
 		{
 			name:    "output has bad syntax",
 			isValid: false,
@@ -103,6 +156,24 @@ func TestStep_Validate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "valid step with when expression",
+			isValid: true,
+			step: manifest.Step{
+				Name:  "migrations",
+				Shell: "date",
+				When:  `{{ eq .Vars.db "postgres" }}`,
+			},
+		},
+		{
+			name:    "when expression validation error",
+			isValid: false,
+			step: manifest.Step{
+				Name:  "migrations",
+				Shell: "date",
+				When:  `{{ eq .Vars.db "postgres" `,
+			},
+		},
 		{
 			name:    "shell script validation error",
 			isValid: false,
@@ -218,6 +289,110 @@ func TestManifest_Validate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "variable declared twice",
+			isValid: false,
+			manifest: manifest.Manifest{
+				Name:    "template-with-variables",
+				Author:  "keanu.reeves@arasaka.net",
+				Version: "1.0.0",
+				Variables: manifest.Variables{
+					{Name: "db"},
+					{Name: "db", Type: manifest.VariableTypeBool},
+				},
+				Steps: []manifest.Step{
+					{
+						Name:  "some valid step",
+						Shell: "date",
+					},
+				},
+			},
+		},
+		{
+			name:    "steps and files inherit delims",
+			isValid: true,
+			manifest: manifest.Manifest{
+				Name:    "template-with-delims",
+				Author:  "keanu.reeves@arasaka.net",
+				Version: "1.0.0",
+				Delims:  manifest.Delims{"[[", "]]"},
+				Steps: []manifest.Step{
+					{
+						Name:  "chart",
+						Shell: "helm lint {{ .Values }",
+						Files: []manifest.File{
+							{Path: "values.yaml", Output: "{{ chart }/[[ .ProjectName ]].yaml"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "delims without right delimiter",
+			isValid: false,
+			manifest: manifest.Manifest{
+				Name:    "template-with-delims",
+				Author:  "keanu.reeves@arasaka.net",
+				Version: "1.0.0",
+				Delims:  manifest.Delims{"[["},
+				Steps: []manifest.Step{
+					{
+						Name:  "some valid step",
+						Shell: "date",
+					},
+				},
+			},
+		},
+		{
+			name:    "file delims take precedence",
+			isValid: false,
+			manifest: manifest.Manifest{
+				Name:    "template-with-delims",
+				Author:  "keanu.reeves@arasaka.net",
+				Version: "1.0.0",
+				Delims:  manifest.Delims{"[[", "]]"},
+				Steps: []manifest.Step{
+					{
+						Name: "chart",
+						Files: []manifest.File{
+							{Path: "values.yaml", Output: "<% .ProjectName >.yaml", Delims: manifest.Delims{"<%", "%>"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "raw has invalid glob pattern",
+			isValid: false,
+			manifest: manifest.Manifest{
+				Name:    "template-with-raw-files",
+				Author:  "keanu.reeves@arasaka.net",
+				Version: "1.0.0",
+				Raw:     []string{"chart/[templates"},
+				Steps: []manifest.Step{
+					{
+						Name:  "some valid step",
+						Shell: "date",
+					},
+				},
+			},
+		},
+		{
+			name:    "step removal without extends",
+			isValid: false,
+			manifest: manifest.Manifest{
+				Name:    "template-without-base",
+				Author:  "keanu.reeves@arasaka.net",
+				Version: "1.0.0",
+				Steps: []manifest.Step{
+					{
+						Name:   "some valid step",
+						Shell:  "date",
+						Remove: true,
+					},
+				},
+			},
+		},
 		{
 			name:    "step validation error",
 			isValid: false,
@@ -249,6 +424,33 @@ func TestManifest_Validate(t *testing.T) {
 	}
 }
 
+func TestManifest_IsRaw(t *testing.T) {
+	m := manifest.Manifest{Raw: []string{"chart/**", "**/*.png"}}
+
+	require.True(t, m.IsRaw("chart/templates/deployment.yaml"))
+	require.True(t, m.IsRaw("assets/img/logo.png"))
+	require.False(t, m.IsRaw("main.go.tpl"))
+}
+
+func TestIsTruthy(t *testing.T) {
+	testCases := map[string]bool{
+		"true":       true,
+		"postgres":   true,
+		"1":          true,
+		"":           false,
+		"  ":         false,
+		"false":      false,
+		"False":      false,
+		"0":          false,
+		"no":         false,
+		"<no value>": false,
+	}
+
+	for rendered, expected := range testCases {
+		require.Equal(t, expected, manifest.IsTruthy(rendered), "rendered value %q", rendered)
+	}
+}
+
 func TestSteps_Get(t *testing.T) {
 	type testCase struct {
 		name     string
@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -22,23 +23,18 @@ func NewRealFSProvider(root string) *RealFSProvider {
 	return &RealFSProvider{root: root}
 }
 
-func (r *RealFSProvider) Get(filename string) ([]byte, error) {
+func (r *RealFSProvider) Get(ctx context.Context, filename string) ([]byte, error) {
 	verbose.Printf("[RealFSProvider] reading %q in %q", filename, r.root)
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	fullPath := filepath.Join(r.root, filename)
 
 	f, err := os.OpenFile(fullPath, os.O_RDONLY, os.ModePerm)
 	if err != nil {
-		errToWrap := err
-
-		switch {
-		case errors.Is(err, fs.ErrNotExist):
-			errToWrap = ErrFileNotFound
-		case errors.Is(err, fs.ErrPermission):
-			errToWrap = ErrPermissionDenied
-		}
-
-		return nil, fmt.Errorf("open %q: %w", fullPath, errToWrap)
+		return nil, fmt.Errorf("open %q: %w", fullPath, wrapFSError(err))
 	}
 	defer f.Close() //nolint:errcheck
 
@@ -49,3 +45,82 @@ func (r *RealFSProvider) Get(filename string) ([]byte, error) {
 
 	return bts, nil
 }
+
+// Mode returns permission bits of file.
+func (r *RealFSProvider) Mode(ctx context.Context, filename string) (fs.FileMode, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	fullPath := filepath.Join(r.root, filename)
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return 0, fmt.Errorf("stat %q: %w", fullPath, wrapFSError(err))
+	}
+
+	return info.Mode().Perm(), nil
+}
+
+// List returns paths of all files inside dir (recursively) relative to dir. Paths are slash-separated.
+func (r *RealFSProvider) List(ctx context.Context, dir string) ([]string, error) {
+	verbose.Printf("[RealFSProvider] listing %q in %q", dir, r.root)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(r.root, dir)
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("stat %q: %w", root, wrapFSError(err))
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("list %q: %w", root, ErrNotDirectory)
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" && p != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list %q: %w", root, wrapFSError(err))
+	}
+
+	return files, nil
+}
+
+// wrapFSError replaces common file system errors with errors of this package.
+func wrapFSError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrFileNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ErrPermissionDenied
+	}
+
+	return err
+}
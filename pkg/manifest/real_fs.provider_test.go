@@ -1,6 +1,7 @@
 package manifest_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -38,7 +39,7 @@ func TestRealFSProvider_Get(t *testing.T) {
 		tc := testCase
 		t.Run(tc.name, func(t *testing.T) {
 			p := manifest.NewRealFSProvider("testdata/")
-			bts, err := p.Get(tc.filename)
+			bts, err := p.Get(context.Background(), tc.filename)
 
 			if tc.isValid {
 				require.NoError(t, err)
@@ -52,3 +53,49 @@ func TestRealFSProvider_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestRealFSProvider_Mode(t *testing.T) {
+	p := manifest.NewRealFSProvider("testdata/")
+
+	mode, err := p.Mode(context.Background(), "run.sh")
+	require.NoError(t, err)
+	require.NotZero(t, mode&0o100, "executable bit is lost: %s", mode)
+
+	mode, err = p.Mode(context.Background(), "hello.txt")
+	require.NoError(t, err)
+	require.Zero(t, mode&0o111, "file is executable: %s", mode)
+
+	_, err = p.Mode(context.Background(), "missing.txt")
+	require.True(t, errors.Is(err, manifest.ErrFileNotFound))
+}
+
+func TestRealFSProvider_List(t *testing.T) {
+	p := manifest.NewRealFSProvider("testdata/")
+
+	t.Run("directory is listed recursively", func(t *testing.T) {
+		files, err := p.List(context.Background(), "embed/go")
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			[]string{
+				"hello-world/projector.toml",
+				"hello-world/projector_invalid.toml",
+				"hello-world/projector_invalid_fields.toml",
+				"hello-world/projector_invalid_syntax.toml",
+			},
+			files,
+		)
+	})
+
+	t.Run("file is not a directory", func(t *testing.T) {
+		files, err := p.List(context.Background(), "hello.txt")
+		require.True(t, errors.Is(err, manifest.ErrNotDirectory))
+		require.Nil(t, files)
+	})
+
+	t.Run("directory does not exist", func(t *testing.T) {
+		files, err := p.List(context.Background(), "python")
+		require.True(t, errors.Is(err, manifest.ErrFileNotFound))
+		require.Nil(t, files)
+	})
+}
@@ -0,0 +1,391 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// source is manifest file with positions of its tables and keys. Positions are keyed by field paths
+// in TOML notation with indexes of array tables, e.g. "steps[0].files[1].output" or "steps[0]"
+// for header of array table.
+type source struct {
+	filename  string
+	positions map[string]Position
+
+	// steps and variables map names of steps and variables declared in file to their indexes.
+	steps     map[string]int
+	variables map[string]int
+
+	// unknown contains errors of keys not declared by manifest structure, e.g. misspelled ones.
+	unknown []*ValidationError
+}
+
+// indexSource collects positions of tables and keys in manifest source. It handles manifests written
+// with array tables ([[steps]], [[steps.files]]) and keys on separate lines; keys of inline tables are
+// not indexed, so errors in them point to the closest indexed parent.
+func indexSource(filename string, src []byte, m *Manifest) *source {
+	s := &source{
+		filename:  filename,
+		positions: map[string]Position{},
+		steps:     map[string]int{},
+		variables: map[string]int{},
+	}
+
+	var (
+		scanner     = bufio.NewScanner(bytes.NewReader(src))
+		counts      = map[string]int{}
+		table       string
+		line        int
+		multilineBy string
+	)
+
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+
+		if multilineBy != "" {
+			if strings.Contains(text, multilineBy) {
+				multilineBy = ""
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(text)
+		column := len(text) - len(strings.TrimLeft(text, " \t")) + 1
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "[[") && strings.HasSuffix(trimmed, "]]"):
+			table = resolveTable(strings.Trim(trimmed, "[] "), counts, true)
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			table = resolveTable(strings.Trim(trimmed, "[] "), counts, false)
+		default:
+			i := strings.Index(trimmed, "=")
+			if i < 0 {
+				continue
+			}
+
+			key := strings.Trim(strings.TrimSpace(trimmed[:i]), `"'`)
+			s.positions[joinPath(table, key)] = Position{Filename: filename, Line: line, Column: column}
+
+			value := trimmed[i+1:]
+			for _, quotes := range []string{`"""`, `'''`} {
+				if strings.Count(value, quotes)%2 == 1 {
+					multilineBy = quotes
+				}
+			}
+			continue
+		}
+
+		s.positions[table] = Position{Filename: filename, Line: line, Column: column}
+	}
+
+	for i, step := range m.Steps {
+		if _, ok := s.steps[step.Name]; !ok {
+			s.steps[step.Name] = i
+		}
+	}
+
+	for i, v := range m.Variables {
+		if _, ok := s.variables[v.Name]; !ok {
+			s.variables[v.Name] = i
+		}
+	}
+
+	return s
+}
+
+// resolveTable returns path of table header with indexes, e.g. "steps[1].files[0]" for second
+// [[steps.files]] header after second [[steps]] header.
+func resolveTable(header string, counts map[string]int, isArray bool) string {
+	var (
+		parts = strings.Split(header, ".")
+		path  string
+	)
+
+	for i, part := range parts {
+		key := joinPath(path, strings.Trim(strings.TrimSpace(part), `"'`))
+
+		if i == len(parts)-1 {
+			if !isArray {
+				return key
+			}
+
+			index := counts[key]
+			counts[key]++
+			return fmt.Sprintf("%s[%d]", key, index)
+		}
+
+		// parent array table refers to its last element
+		if count, ok := counts[key]; ok {
+			path = fmt.Sprintf("%s[%d]", key, count-1)
+			continue
+		}
+
+		path = key
+	}
+
+	return path
+}
+
+// find returns position of field or of its closest indexed parent.
+func (s *source) find(field string) (Position, bool) {
+	for {
+		if pos, ok := s.positions[field]; ok {
+			return pos, true
+		}
+
+		i := strings.LastIndexAny(field, ".[")
+		if i < 0 {
+			return Position{}, false
+		}
+		field = field[:i]
+	}
+}
+
+// locate sets position of validation error of manifest m made of sources. The first source is file
+// of m itself and others are files of manifests it extends, steps and variables are looked up by name.
+func locate(e *ValidationError, m *Manifest, sources []*source) {
+	e.Position = Position{Filename: sources[0].filename}
+
+	// indexes are the same as in source if manifest doesn't extend other manifests
+	if len(sources) == 1 {
+		if pos, ok := sources[0].find(e.Field); ok {
+			e.Position = pos
+		}
+		return
+	}
+
+	for _, src := range sources {
+		field, ok := src.field(e, m)
+		if !ok {
+			continue
+		}
+
+		if pos, ok := src.find(field); ok {
+			e.Position = pos
+			return
+		}
+	}
+}
+
+// Locator finds fields of loaded manifest in manifest files it is made of.
+type Locator struct {
+	manifest *Manifest
+	sources  []*source
+}
+
+// Locate returns position of field in TOML notation, e.g. "steps[1].files[0].output", or of its closest
+// parent found in manifest files. Indexes of steps and variables are ones of loaded manifest.
+func (l *Locator) Locate(field string) Position {
+	return l.Error(field, "").Position
+}
+
+// Error makes validation error of field in TOML notation positioned like Locate does. Step and file
+// of error are taken from field path.
+func (l *Locator) Error(field, message string) *ValidationError {
+	e := &ValidationError{Field: field, Step: -1, File: -1, Message: message, variable: -1}
+
+	if match := arrayIndexes.FindStringSubmatch(field); match != nil {
+		e.Step, _ = strconv.Atoi(match[1])
+		if e.Step < len(l.manifest.Steps) {
+			e.stepName = l.manifest.Steps[e.Step].Name
+		}
+
+		if match[2] != "" {
+			e.File, _ = strconv.Atoi(match[2])
+		}
+	}
+
+	if match := variableIndex.FindStringSubmatch(field); match != nil {
+		e.variable, _ = strconv.Atoi(match[1])
+	}
+
+	locate(e, l.manifest, l.sources)
+	return e
+}
+
+// field translates path of field in merged manifest m into path in source.
+func (s *source) field(e *ValidationError, m *Manifest) (string, bool) {
+	switch {
+	case e.Step >= 0 && e.Step < len(m.Steps):
+		i, ok := s.steps[m.Steps[e.Step].Name]
+		return reindex(e.Field, "steps", e.Step, i), ok
+	case e.variable >= 0 && e.variable < len(m.Variables):
+		i, ok := s.variables[m.Variables[e.variable].Name]
+		return reindex(e.Field, "variables", e.variable, i), ok
+	default:
+		return e.Field, true
+	}
+}
+
+// reindex replaces index of array element in the beginning of field path.
+func reindex(field, array string, from, to int) string {
+	return fmt.Sprintf("%s[%d]", array, to) + strings.TrimPrefix(field, fmt.Sprintf("%s[%d]", array, from))
+}
+
+// unknownKeys makes errors of keys left undecoded, one per occurrence in source.
+// Closest known key is suggested if key looks misspelled.
+func (s *source) unknownKeys(keys []toml.Key, m *Manifest) []*ValidationError {
+	var (
+		result   []*ValidationError
+		reported = map[string]struct{}{}
+	)
+
+	for _, key := range keys {
+		name := key.String()
+		if _, ok := reported[name]; ok {
+			continue
+		}
+		reported[name] = struct{}{}
+
+		var fields []string
+		for field := range s.positions {
+			if arrayIndex.ReplaceAllString(field, "") == name {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+
+		if len(fields) == 0 {
+			fields = []string{name}
+		}
+
+		for _, field := range fields {
+			e := &ValidationError{
+				Field:    field,
+				Step:     -1,
+				File:     -1,
+				Message:  fmt.Sprintf("unknown key %q", key[len(key)-1]),
+				variable: -1,
+			}
+			e.Position, _ = s.find(field)
+			e.Position.Filename = s.filename
+
+			if match := arrayIndexes.FindStringSubmatch(field); match != nil {
+				if i, err := strconv.Atoi(match[1]); err == nil && i < len(m.Steps) {
+					e.Step, e.stepName = i, m.Steps[i].Name
+				}
+
+				if match[2] != "" {
+					e.File, _ = strconv.Atoi(match[2])
+				}
+			}
+
+			if suggestion := closestKey(key[len(key)-1], knownKeys(reflect.TypeOf(Manifest{}), key[:len(key)-1])); suggestion != "" {
+				e.Suggestion = fmt.Sprintf("did you mean %q?", suggestion)
+			}
+
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+var (
+	arrayIndex    = regexp.MustCompile(`\[\d+\]`)
+	arrayIndexes  = regexp.MustCompile(`^steps\[(\d+)\](?:\.files\[(\d+)\])?`)
+	variableIndex = regexp.MustCompile(`^variables\[(\d+)\]`)
+)
+
+// knownKeys returns TOML keys of fields of table located at path inside structure t.
+func knownKeys(t reflect.Type, path []string) []string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("toml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+
+		if len(path) > 0 && key == path[0] {
+			return knownKeys(t.Field(i).Type, path[1:])
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(path) > 0 {
+		return nil
+	}
+
+	return keys
+}
+
+// closestKey returns known key within edit distance of 2 from key.
+func closestKey(key string, known []string) string {
+	var (
+		closest string
+		best    = 3
+	)
+
+	for _, k := range known {
+		if d := editDistance(key, k); d < best {
+			closest, best = k, d
+		}
+	}
+
+	return closest
+}
+
+// editDistance returns Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+
+	return result
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+
+	return parent + "." + key
+}
@@ -0,0 +1,270 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Supported types of template variables.
+const (
+	VariableTypeString = "string"
+	VariableTypeBool   = "bool"
+	VariableTypeInt    = "int"
+	VariableTypeEnum   = "enum"
+	VariableTypeList   = "list"
+)
+
+var variableTypes = []string{
+	VariableTypeString,
+	VariableTypeBool,
+	VariableTypeInt,
+	VariableTypeEnum,
+	VariableTypeList,
+}
+
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Variable is custom typed value declared by template. Resolved variables are exposed
+// to file, output path and shell templates as `.Vars.<name>`.
+type Variable struct {
+	Name        string      `toml:"name"`
+	Type        string      `toml:"type,omitempty"`
+	Default     interface{} `toml:"default,omitempty"`
+	Description string      `toml:"description,omitempty"`
+	Options     []string    `toml:"options,omitempty"`
+}
+
+func (v Variable) Validate() error {
+	return joinErrors(v.validationErrors())
+}
+
+func (v Variable) validationErrors() []*ValidationError {
+	var result []*ValidationError
+
+	if err := validation.ValidateStruct(
+		&v,
+		validation.Field(
+			&v.Name,
+			validation.Required,
+			validation.Match(variableNamePattern).Error("must be a valid identifier"),
+		),
+		validation.Field(
+			&v.Type,
+			validation.In(
+				VariableTypeString,
+				VariableTypeBool,
+				VariableTypeInt,
+				VariableTypeEnum,
+				VariableTypeList,
+			),
+		),
+		validation.Field(&v.Options, validation.When(v.Type == VariableTypeEnum, validation.Required)),
+	); err != nil {
+		for _, e := range fieldErrors(&v, err) {
+			if e.Field == "type" {
+				e.Suggestion = "use one of: " + strings.Join(variableTypes, ", ")
+			}
+			result = append(result, e)
+		}
+	}
+
+	if v.Default != nil {
+		if _, err := v.normalize(v.Default); err != nil {
+			result = append(result, newValidationError("default", err))
+		}
+	}
+
+	return result
+}
+
+// Kind returns type of variable, string is used if type is not specified.
+func (v Variable) Kind() string {
+	if v.Type == "" {
+		return VariableTypeString
+	}
+
+	return v.Type
+}
+
+// Parse converts raw string value (e.g. passed from command line) to value of variable's type.
+// Values of list variables are separated with commas.
+func (v Variable) Parse(raw string) (interface{}, error) {
+	switch v.Kind() {
+	case VariableTypeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a bool", raw)
+		}
+		return b, nil
+	case VariableTypeInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an int", raw)
+		}
+		return n, nil
+	case VariableTypeList:
+		if strings.TrimSpace(raw) == "" {
+			return []string{}, nil
+		}
+
+		items := strings.Split(raw, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return items, nil
+	}
+
+	return v.normalize(raw)
+}
+
+// DefaultValue returns default value of variable converted to variable's type.
+// Zero value of type is used if default is not set, enum variables fall back to the first option.
+func (v Variable) DefaultValue() (interface{}, error) {
+	if v.Default != nil {
+		return v.normalize(v.Default)
+	}
+
+	switch v.Kind() {
+	case VariableTypeBool:
+		return false, nil
+	case VariableTypeInt:
+		return 0, nil
+	case VariableTypeList:
+		return []string{}, nil
+	case VariableTypeEnum:
+		if len(v.Options) > 0 {
+			return v.Options[0], nil
+		}
+	}
+
+	return "", nil
+}
+
+// normalize converts value decoded from TOML to canonical Go type of variable.
+func (v Variable) normalize(value interface{}) (interface{}, error) {
+	switch v.Kind() {
+	case VariableTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case VariableTypeBool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case VariableTypeInt:
+		switch n := value.(type) {
+		case int:
+			return n, nil
+		case int64:
+			return int(n), nil
+		}
+	case VariableTypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			break
+		}
+
+		for _, option := range v.Options {
+			if s == option {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %v", s, v.Options)
+	case VariableTypeList:
+		switch items := value.(type) {
+		case []string:
+			return items, nil
+		case []interface{}:
+			list := make([]string, 0, len(items))
+			for _, item := range items {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("list item %v is not a string", item)
+				}
+				list = append(list, s)
+			}
+			return list, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown variable type %q", v.Type)
+	}
+
+	return nil, fmt.Errorf("%v is not a %s", value, v.Kind())
+}
+
+type Variables []Variable
+
+func (vs Variables) Validate() error {
+	return joinErrors(vs.validationErrors())
+}
+
+func (vs Variables) validationErrors() []*ValidationError {
+	var (
+		result []*ValidationError
+		seen   = map[string]struct{}{}
+	)
+
+	for i, v := range vs {
+		errs := v.validationErrors()
+
+		if _, ok := seen[v.Name]; ok {
+			e := newValidationError("name", fmt.Errorf("variable %q is declared more than once", v.Name))
+			e.Message = e.Err.Error()
+			errs = append(errs, e)
+		}
+		seen[v.Name] = struct{}{}
+
+		for _, e := range nest(fmt.Sprintf("variables[%d]", i), errs) {
+			e.variable = i
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+func (vs Variables) Get(name string) (*Variable, error) {
+	for _, v := range vs {
+		if v.Name == name {
+			return &v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown variable %q", name)
+}
+
+// Resolve builds template variables from raw values passed by user. Variables not passed in raw
+// get their default values. Error is returned if raw contains undeclared variable or invalid value.
+func (vs Variables) Resolve(raw map[string]string) (map[string]interface{}, error) {
+	for name := range raw {
+		if _, err := vs.Get(name); err != nil {
+			return nil, err
+		}
+	}
+
+	resolved := make(map[string]interface{}, len(vs))
+	for _, v := range vs {
+		var (
+			value interface{}
+			err   error
+		)
+
+		if rawValue, ok := raw[v.Name]; ok {
+			value, err = v.Parse(rawValue)
+		} else {
+			value, err = v.DefaultValue()
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", v.Name, err)
+		}
+
+		resolved[v.Name] = value
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,241 @@
+package manifest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+func TestVariable_Validate(t *testing.T) {
+	type testCase struct {
+		name     string
+		isValid  bool
+		variable manifest.Variable
+	}
+
+	testCases := []testCase{
+		{
+			name:     "valid string variable without type",
+			isValid:  true,
+			variable: manifest.Variable{Name: "license", Default: "MIT"},
+		},
+		{
+			name:    "valid enum variable",
+			isValid: true,
+			variable: manifest.Variable{
+				Name:    "db",
+				Type:    manifest.VariableTypeEnum,
+				Options: []string{"postgres", "mysql"},
+				Default: "mysql",
+			},
+		},
+		{
+			name:     "valid int variable with default decoded from toml",
+			isValid:  true,
+			variable: manifest.Variable{Name: "port", Type: manifest.VariableTypeInt, Default: int64(8080)},
+		},
+		{
+			name:    "valid list variable with default decoded from toml",
+			isValid: true,
+			variable: manifest.Variable{
+				Name:    "tags",
+				Type:    manifest.VariableTypeList,
+				Default: []interface{}{"api", "backend"},
+			},
+		},
+		{
+			name:     "name is not set",
+			isValid:  false,
+			variable: manifest.Variable{Default: "MIT"},
+		},
+		{
+			name:     "name is not an identifier",
+			isValid:  false,
+			variable: manifest.Variable{Name: "db-engine"},
+		},
+		{
+			name:     "unknown type",
+			isValid:  false,
+			variable: manifest.Variable{Name: "ratio", Type: "float"},
+		},
+		{
+			name:     "enum without options",
+			isValid:  false,
+			variable: manifest.Variable{Name: "db", Type: manifest.VariableTypeEnum},
+		},
+		{
+			name:    "enum default is not an option",
+			isValid: false,
+			variable: manifest.Variable{
+				Name:    "db",
+				Type:    manifest.VariableTypeEnum,
+				Options: []string{"postgres", "mysql"},
+				Default: "oracle",
+			},
+		},
+		{
+			name:     "default does not match type",
+			isValid:  false,
+			variable: manifest.Variable{Name: "port", Type: manifest.VariableTypeInt, Default: "8080"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.variable.Validate()
+
+			if tc.isValid {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestVariable_Parse(t *testing.T) {
+	type testCase struct {
+		name     string
+		isValid  bool
+		variable manifest.Variable
+		raw      string
+		expected interface{}
+	}
+
+	testCases := []testCase{
+		{
+			name:     "string",
+			isValid:  true,
+			variable: manifest.Variable{Name: "license"},
+			raw:      "MIT",
+			expected: "MIT",
+		},
+		{
+			name:     "bool",
+			isValid:  true,
+			variable: manifest.Variable{Name: "docker", Type: manifest.VariableTypeBool},
+			raw:      "true",
+			expected: true,
+		},
+		{
+			name:     "int",
+			isValid:  true,
+			variable: manifest.Variable{Name: "port", Type: manifest.VariableTypeInt},
+			raw:      "3000",
+			expected: 3000,
+		},
+		{
+			name:     "list",
+			isValid:  true,
+			variable: manifest.Variable{Name: "tags", Type: manifest.VariableTypeList},
+			raw:      "api, backend",
+			expected: []string{"api", "backend"},
+		},
+		{
+			name:     "enum",
+			isValid:  true,
+			variable: manifest.Variable{Name: "db", Type: manifest.VariableTypeEnum, Options: []string{"postgres"}},
+			raw:      "postgres",
+			expected: "postgres",
+		},
+		{
+			name:     "invalid bool",
+			isValid:  false,
+			variable: manifest.Variable{Name: "docker", Type: manifest.VariableTypeBool},
+			raw:      "maybe",
+		},
+		{
+			name:     "invalid int",
+			isValid:  false,
+			variable: manifest.Variable{Name: "port", Type: manifest.VariableTypeInt},
+			raw:      "eighty",
+		},
+		{
+			name:     "unknown enum option",
+			isValid:  false,
+			variable: manifest.Variable{Name: "db", Type: manifest.VariableTypeEnum, Options: []string{"postgres"}},
+			raw:      "oracle",
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.variable.Parse(tc.raw)
+
+			if tc.isValid {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, actual)
+				return
+			}
+
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestVariables_Resolve(t *testing.T) {
+	type testCase struct {
+		name     string
+		isValid  bool
+		raw      map[string]string
+		expected map[string]interface{}
+	}
+
+	vars := manifest.Variables{
+		{Name: "db", Type: manifest.VariableTypeEnum, Options: []string{"postgres", "mysql"}},
+		{Name: "port", Type: manifest.VariableTypeInt, Default: int64(8080)},
+		{Name: "license", Default: "MIT"},
+	}
+
+	testCases := []testCase{
+		{
+			name:    "defaults are used if nothing passed",
+			isValid: true,
+			expected: map[string]interface{}{
+				"db":      "postgres",
+				"port":    8080,
+				"license": "MIT",
+			},
+		},
+		{
+			name:    "passed values override defaults",
+			isValid: true,
+			raw:     map[string]string{"db": "mysql", "port": "3306"},
+			expected: map[string]interface{}{
+				"db":      "mysql",
+				"port":    3306,
+				"license": "MIT",
+			},
+		},
+		{
+			name:    "undeclared variable",
+			isValid: false,
+			raw:     map[string]string{"cache": "redis"},
+		},
+		{
+			name:    "invalid value",
+			isValid: false,
+			raw:     map[string]string{"port": "http"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := vars.Resolve(tc.raw)
+
+			if tc.isValid {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, actual)
+				return
+			}
+
+			require.Error(t, err)
+			require.Nil(t, actual)
+		})
+	}
+}
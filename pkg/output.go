@@ -0,0 +1,250 @@
+package projector
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Output is file system generated project is written to, e.g. directory on disk, memory or archive.
+// Relative paths are resolved against root of output. Errors of missing files wrap fs.ErrNotExist.
+type Output interface {
+	MkdirAll(path string, perm fs.FileMode) error
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (fs.FileInfo, error)
+	Chmod(name string, mode fs.FileMode) error
+	Remove(name string) error
+}
+
+// dirOutput is implemented by outputs located in directory on disk. Shell scripts can be executed only there.
+type dirOutput interface {
+	Dir() string
+}
+
+// DirOutput writes files into directory on disk. Relative paths are resolved against the directory
+// instead of current directory.
+type DirOutput struct {
+	dir string
+}
+
+func NewDirOutput(dir string) *DirOutput {
+	return &DirOutput{dir: dir}
+}
+
+// Dir returns directory output is written to.
+func (o *DirOutput) Dir() string {
+	return o.dir
+}
+
+func (o *DirOutput) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(o.path(path), perm)
+}
+
+func (o *DirOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(o.path(name), data, perm)
+}
+
+func (o *DirOutput) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(o.path(name))
+}
+
+func (o *DirOutput) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(o.path(name))
+}
+
+func (o *DirOutput) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(o.path(name), mode)
+}
+
+func (o *DirOutput) Remove(name string) error {
+	return os.Remove(o.path(name))
+}
+
+func (o *DirOutput) path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+
+	return filepath.Join(o.dir, name)
+}
+
+// MemOutput keeps generated files in memory. It is used for dry runs and to render templates during update.
+type MemOutput struct {
+	entries map[string]*memEntry
+}
+
+func NewMemOutput() *MemOutput {
+	return &MemOutput{entries: map[string]*memEntry{}}
+}
+
+func (o *MemOutput) MkdirAll(path string, perm fs.FileMode) error {
+	name := filepath.Clean(path)
+	if name == "." || filepath.Dir(name) == name {
+		return nil
+	}
+
+	if e, ok := o.entries[name]; ok {
+		if !e.IsDir() {
+			return fmt.Errorf("mkdir %q: not a directory", path)
+		}
+		return nil
+	}
+
+	if err := o.MkdirAll(filepath.Dir(name), perm); err != nil {
+		return err
+	}
+
+	o.entries[name] = &memEntry{name: filepath.Base(name), mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// WriteFile writes file creating missing parent directories.
+func (o *MemOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	key := filepath.Clean(name)
+	if e, ok := o.entries[key]; ok {
+		if e.IsDir() {
+			return fmt.Errorf("write %q: is a directory", name)
+		}
+
+		e.data, e.modTime = append([]byte(nil), data...), time.Now()
+		return nil
+	}
+
+	if err := o.MkdirAll(filepath.Dir(key), dirMode); err != nil {
+		return err
+	}
+
+	o.entries[key] = &memEntry{
+		name:    filepath.Base(key),
+		data:    append([]byte(nil), data...),
+		mode:    perm.Perm(),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+func (o *MemOutput) ReadFile(name string) ([]byte, error) {
+	e, err := o.entry("read", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.IsDir() {
+		return nil, fmt.Errorf("read %q: is a directory", name)
+	}
+
+	return append([]byte(nil), e.data...), nil
+}
+
+func (o *MemOutput) Stat(name string) (fs.FileInfo, error) {
+	e, err := o.entry("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	info := *e
+	return &info, nil
+}
+
+func (o *MemOutput) Chmod(name string, mode fs.FileMode) error {
+	e, err := o.entry("chmod", name)
+	if err != nil {
+		return err
+	}
+
+	e.mode = e.mode.Type() | mode.Perm()
+	return nil
+}
+
+// Remove removes file or empty directory.
+func (o *MemOutput) Remove(name string) error {
+	e, err := o.entry("remove", name)
+	if err != nil {
+		return err
+	}
+
+	key := filepath.Clean(name)
+	if e.IsDir() {
+		for p := range o.entries {
+			if strings.HasPrefix(p, key+string(filepath.Separator)) {
+				return fmt.Errorf("remove %q: directory not empty", name)
+			}
+		}
+	}
+
+	delete(o.entries, key)
+	return nil
+}
+
+// symlink creates symbolic link name pointing to target creating missing parent directories.
+func (o *MemOutput) symlink(name, target string) error {
+	key := filepath.Clean(name)
+	if _, ok := o.entries[key]; ok {
+		return &fs.PathError{Op: "symlink", Path: name, Err: fs.ErrExist}
+	}
+
+	if err := o.MkdirAll(filepath.Dir(key), dirMode); err != nil {
+		return err
+	}
+
+	o.entries[key] = &memEntry{
+		name:    filepath.Base(key),
+		data:    []byte(target),
+		mode:    fs.ModeSymlink | 0o777,
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+// Files returns content of written files keyed by their cleaned paths.
+func (o *MemOutput) Files() map[string][]byte {
+	files := map[string][]byte{}
+	for p, e := range o.entries {
+		if e.mode.IsRegular() {
+			files[p] = e.data
+		}
+	}
+
+	return files
+}
+
+// paths returns paths of all entries sorted so that directories precede their content.
+func (o *MemOutput) paths() []string {
+	paths := make([]string, 0, len(o.entries))
+	for p := range o.entries {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+func (o *MemOutput) entry(op, name string) (*memEntry, error) {
+	e, ok := o.entries[filepath.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	return e, nil
+}
+
+// memEntry is file, directory or symbolic link of MemOutput. It is fs.FileInfo of itself.
+// Data of symbolic link is its target.
+type memEntry struct {
+	name    string
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (e *memEntry) Name() string       { return e.name }
+func (e *memEntry) Size() int64        { return int64(len(e.data)) }
+func (e *memEntry) Mode() fs.FileMode  { return e.mode }
+func (e *memEntry) ModTime() time.Time { return e.modTime }
+func (e *memEntry) IsDir() bool        { return e.mode.IsDir() }
+func (e *memEntry) Sys() interface{}   { return nil }
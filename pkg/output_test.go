@@ -0,0 +1,44 @@
+package projector_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	projector "github.com/tomakado/projector/pkg"
+)
+
+func TestMemOutput(t *testing.T) {
+	o := projector.NewMemOutput()
+
+	require.NoError(t, o.MkdirAll("cmd/app", 0o755))
+	require.NoError(t, o.WriteFile("cmd/app/main.go", []byte("package main\n"), 0o644))
+	require.NoError(t, o.WriteFile("docs/README.md", []byte("# app\n"), 0o644))
+
+	content, err := o.ReadFile("cmd/app/main.go")
+	require.NoError(t, err)
+	require.Equal(t, "package main\n", string(content))
+
+	info, err := o.Stat("docs")
+	require.NoError(t, err)
+	require.True(t, info.IsDir(), "parent directory is created by WriteFile")
+
+	require.NoError(t, o.Chmod("cmd/app/main.go", 0o600))
+	info, err = o.Stat("cmd/app/main.go")
+	require.NoError(t, err)
+	require.Equal(t, fs.FileMode(0o600), info.Mode())
+	require.Equal(t, int64(13), info.Size())
+
+	_, err = o.Stat("missing.txt")
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+
+	require.Error(t, o.WriteFile("cmd", nil, 0o644), "directory is not overwritten by file")
+	require.Error(t, o.Remove("cmd/app"), "directory is not empty")
+
+	require.NoError(t, o.Remove("cmd/app/main.go"))
+	require.NoError(t, o.Remove("cmd/app"))
+	require.True(t, errors.Is(o.Remove("cmd/app"), fs.ErrNotExist))
+
+	require.Equal(t, map[string][]byte{"docs/README.md": []byte("# app\n")}, o.Files())
+}
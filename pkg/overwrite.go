@@ -0,0 +1,152 @@
+package projector
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/tomakado/projector/internal/pkg/verbose"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+// resolveConflict applies overwrite policy if file already exists at output path.
+// It returns content to write and false if file must be left untouched.
+func (g *Generator) resolveConflict(f manifest.File, outputPath string, data []byte) ([]byte, bool, error) {
+	existing := g.existingFiles()
+	info, err := existing.Stat(outputPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return data, true, nil
+		}
+		return nil, false, fmt.Errorf("stat %q: %w", outputPath, err)
+	}
+
+	policy := g.overwritePolicy(f)
+	verbose.Printf("%q already exists, applying overwrite policy %q", outputPath, policy)
+
+	if policy == manifest.OverwritePrompt {
+		if policy, err = g.askOverwritePolicy(outputPath); err != nil {
+			return nil, false, err
+		}
+	}
+
+	switch policy {
+	case manifest.OverwriteFail:
+		return nil, false, fmt.Errorf("%q: %w", outputPath, ErrFileExists)
+	case manifest.OverwriteSkip:
+		verbose.Printf("keeping existing %q", outputPath)
+		return nil, false, nil
+	case manifest.OverwriteAlways:
+		return data, true, nil
+	case manifest.OverwriteBackup:
+		backupPath, err := backupPath(existing, outputPath)
+		if err != nil {
+			return nil, false, err
+		}
+
+		verbose.Printf("copying existing %q to %q", outputPath, backupPath)
+		content, err := existing.ReadFile(outputPath)
+		if err != nil {
+			return nil, false, fmt.Errorf("backup %q: %w", outputPath, err)
+		}
+
+		if err := g.output.WriteFile(backupPath, content, info.Mode().Perm()); err != nil {
+			return nil, false, fmt.Errorf("backup %q: %w", outputPath, err)
+		}
+		return data, true, nil
+	case manifest.OverwriteMerge:
+		content, err := existing.ReadFile(outputPath)
+		if err != nil {
+			return nil, false, fmt.Errorf("read existing %q: %w", outputPath, err)
+		}
+		return mergeLines(content, data), true, nil
+	default:
+		return nil, false, manifest.ValidateOverwritePolicy(policy)
+	}
+}
+
+// overwritePolicy returns policy of generation run if it is set explicitly, so template can't replace files
+// user asked to keep. Otherwise policy declared by file is used, and conflicting files fail generation if file
+// has none.
+func (g *Generator) overwritePolicy(f manifest.File) string {
+	if g.config.Overwrite != "" {
+		return g.config.Overwrite
+	}
+
+	if f.Overwrite != "" {
+		return f.Overwrite
+	}
+
+	return manifest.OverwriteFail
+}
+
+func (g *Generator) askOverwritePolicy(outputPath string) (string, error) {
+	if g.asker == nil {
+		return "", fmt.Errorf("%q: %w, unable to ask how to resolve conflict", outputPath, ErrFileExists)
+	}
+
+	return g.asker.Select(
+		fmt.Sprintf("File %q already exists", outputPath),
+		[]string{
+			manifest.OverwriteSkip,
+			manifest.OverwriteAlways,
+			manifest.OverwriteBackup,
+			manifest.OverwriteMerge,
+			manifest.OverwriteFail,
+		},
+		manifest.OverwriteSkip,
+	)
+}
+
+// existingFiles returns output existing files are looked up in. Nothing is written in dry run,
+// but conflicts with files of working directory are reported anyway.
+func (g *Generator) existingFiles() Output {
+	if g.config.DryRun {
+		return NewDirOutput(g.config.WorkingDirectory)
+	}
+
+	return g.output
+}
+
+// backupPath returns first name free in output among <name>.bak, <name>.bak.1, <name>.bak.2 and so on.
+func backupPath(output Output, name string) (string, error) {
+	candidate := name + ".bak"
+	for i := 1; ; i++ {
+		_, err := output.Stat(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			return candidate, nil
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("stat %q: %w", candidate, err)
+		}
+
+		candidate = fmt.Sprintf("%s.bak.%d", name, i)
+	}
+}
+
+// mergeLines appends lines of generated content missing in existing content. Order and duplicates of lines
+// aren't tracked, so it only suits list-like files such as .gitignore or .dockerignore.
+func mergeLines(existing, generated []byte) []byte {
+	present := map[string]struct{}{}
+	for _, line := range strings.Split(string(existing), "\n") {
+		present[line] = struct{}{}
+	}
+
+	merged := bytes.NewBuffer(append([]byte(nil), existing...))
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		merged.WriteByte('\n')
+	}
+
+	for _, line := range strings.SplitAfter(string(generated), "\n") {
+		if _, ok := present[strings.TrimSuffix(line, "\n")]; ok || line == "" {
+			continue
+		}
+
+		merged.WriteString(line)
+	}
+
+	return merged.Bytes()
+}
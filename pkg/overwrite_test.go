@@ -0,0 +1,158 @@
+package projector_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+func TestCreate_Overwrite(t *testing.T) {
+	type testCase struct {
+		name           string
+		existing       map[string]string
+		policy         string
+		dryRun         bool
+		asker          projector.Asker
+		expectedErr    error
+		expectedFiles  map[string]string
+		expectedBackup string
+	}
+
+	const (
+		existingReadme    = "hand-written readme\n"
+		existingGitignore = ".env\n.idea/\n"
+		generatedReadme   = "# app\n"
+		generatedIgnore   = "bin/\n.env\n"
+		mergedGitignore   = ".env\n.idea/\nbin/\n"
+	)
+
+	onlyGitignore := map[string]string{".gitignore": existingGitignore}
+
+	testCases := []testCase{
+		{
+			name:          "fail policy returns error",
+			policy:        manifest.OverwriteFail,
+			expectedErr:   projector.ErrFileExists,
+			expectedFiles: map[string]string{"README.md": existingReadme},
+		},
+		{
+			name:          "fail policy returns error in dry run",
+			policy:        manifest.OverwriteFail,
+			dryRun:        true,
+			expectedErr:   projector.ErrFileExists,
+			expectedFiles: map[string]string{"README.md": existingReadme, ".gitignore": existingGitignore},
+		},
+		{
+			name:          "no policy fails",
+			expectedErr:   projector.ErrFileExists,
+			expectedFiles: map[string]string{"README.md": existingReadme, ".gitignore": existingGitignore},
+		},
+		{
+			name:          "policy of file is used without policy of run",
+			existing:      onlyGitignore,
+			expectedFiles: map[string]string{"README.md": generatedReadme, ".gitignore": mergedGitignore},
+		},
+		{
+			name:          "skip policy of run takes precedence over policy of file",
+			policy:        manifest.OverwriteSkip,
+			expectedFiles: map[string]string{"README.md": existingReadme, ".gitignore": existingGitignore},
+		},
+		{
+			name:          "fail policy of run takes precedence over policy of file",
+			existing:      onlyGitignore,
+			policy:        manifest.OverwriteFail,
+			expectedErr:   projector.ErrFileExists,
+			expectedFiles: map[string]string{".gitignore": existingGitignore},
+		},
+		{
+			name:          "overwrite policy replaces existing files",
+			policy:        manifest.OverwriteAlways,
+			expectedFiles: map[string]string{"README.md": generatedReadme, ".gitignore": generatedIgnore},
+		},
+		{
+			name:           "backup policy keeps copy of existing file",
+			policy:         manifest.OverwriteBackup,
+			expectedFiles:  map[string]string{"README.md": generatedReadme},
+			expectedBackup: existingReadme,
+		},
+		{
+			name:   "prompt policy asks user",
+			policy: manifest.OverwritePrompt,
+			asker: &fakeAsker{
+				answers:  map[string]string{`File "README.md" already exists`: manifest.OverwriteAlways},
+				generate: true,
+			},
+			expectedFiles: map[string]string{"README.md": generatedReadme},
+		},
+		{
+			name:          "prompt policy fails without asker",
+			policy:        manifest.OverwritePrompt,
+			expectedErr:   projector.ErrFileExists,
+			expectedFiles: map[string]string{"README.md": existingReadme},
+		},
+		{
+			name:        "unknown policy",
+			policy:      "ignore",
+			expectedErr: errors.New(`unknown overwrite policy "ignore", expected one of: fail, skip, overwrite, prompt, backup, merge`),
+		},
+	}
+
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+	p := manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata"))
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			existing := tc.existing
+			if existing == nil {
+				existing = map[string]string{"README.md": existingReadme, ".gitignore": existingGitignore}
+			}
+
+			wd := t.TempDir()
+			for name, content := range existing {
+				require.NoError(t, os.WriteFile(filepath.Join(wd, name), []byte(content), 0o644))
+			}
+
+			_, err := projector.Create(context.Background(), projector.CreateConfig{
+				Config: &projector.Config{
+					WorkingDirectory: wd,
+					ProjectName:      "app",
+					ProjectAuthor:    "tomakado",
+					Overwrite:        tc.policy,
+					DryRun:           tc.dryRun,
+				},
+				Provider:       p,
+				PathToManifest: "overwrite",
+				Asker:          tc.asker,
+			})
+
+			switch {
+			case tc.expectedErr == projector.ErrFileExists:
+				require.True(t, errors.Is(err, projector.ErrFileExists), "unexpected error: %v", err)
+			case tc.expectedErr != nil:
+				require.EqualError(t, err, tc.expectedErr.Error())
+			default:
+				require.NoError(t, err)
+			}
+
+			for name, expected := range tc.expectedFiles {
+				actual, err := os.ReadFile(filepath.Join(wd, name))
+				require.NoError(t, err)
+				require.Equal(t, expected, string(actual), name)
+			}
+
+			if tc.expectedBackup != "" {
+				actual, err := os.ReadFile(filepath.Join(wd, "README.md.bak"))
+				require.NoError(t, err)
+				require.Equal(t, tc.expectedBackup, string(actual))
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package projector
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+
+	"github.com/tomakado/projector/internal/pkg/verbose"
+)
+
+// runCommand runs cmd and returns its combined output. Command is started in its own process group, so
+// if ctx is done, the whole group is killed, including processes spawned by command, and ctx.Err() is returned.
+func runCommand(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return output.Bytes(), err
+	case <-ctx.Done():
+		verbose.Printf("killing process group of %q", cmd.String())
+		if err := killProcessGroup(cmd); err != nil {
+			verbose.Printf("failed to kill process group: %v", err)
+		}
+
+		<-done
+		return output.Bytes(), ctx.Err()
+	}
+}
@@ -0,0 +1,10 @@
+package projector
+
+import "os/exec"
+
+// setProcessGroup does nothing on Windows, only the command itself is killed when context is done.
+func setProcessGroup(*exec.Cmd) {}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
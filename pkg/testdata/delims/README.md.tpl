@@ -0,0 +1 @@
+# <% .ProjectName %> [[ .ProjectName ]]
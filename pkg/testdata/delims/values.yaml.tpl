@@ -0,0 +1,2 @@
+name: [[ .ProjectName ]]
+image: {{ .Values.image }}
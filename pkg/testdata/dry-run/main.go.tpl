@@ -0,0 +1,3 @@
+package main
+
+// {{ .ProjectName }}
@@ -0,0 +1 @@
+# {{ .ProjectName }} from builtin
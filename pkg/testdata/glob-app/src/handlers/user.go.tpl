@@ -0,0 +1,4 @@
+package handlers
+
+// User handles {{ .ProjectName }} users.
+type User struct{}
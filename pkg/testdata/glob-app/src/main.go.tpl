@@ -0,0 +1,4 @@
+package main
+
+// {{ .ProjectName }}
+func main() {}
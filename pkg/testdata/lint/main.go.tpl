@@ -0,0 +1,9 @@
+package main
+
+// {{ .ProjectName }} by {{ .ProjectAuthor }}
+func main() {
+	{{- range .Manifest.Steps }}
+	// {{ .Name }} at {{ $.Manifest.Versoin }}
+	{{- end }}
+	println("{{ .Vars.port }}", "{{ .Vars.db }}", "{{ .Vars.dbb }}")
+}
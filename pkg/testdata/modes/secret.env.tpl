@@ -0,0 +1 @@
+token=
@@ -0,0 +1,3 @@
+# {{ .ProjectName }} by {{ .ProjectAuthor }}
+
+{{ .Vars.db }}:{{ .Vars.port }} docker={{ .Vars.docker }}
@@ -0,0 +1,7 @@
+# {{ .ProjectName }}
+
+Generated by template.
+
+## Usage
+
+Run the app.
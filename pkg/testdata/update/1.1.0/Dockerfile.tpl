@@ -0,0 +1,2 @@
+FROM golang
+EXPOSE {{ .Vars.port }}
@@ -0,0 +1,513 @@
+package projector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tomakado/projector/internal/pkg/verbose"
+)
+
+// journal is output writing generated files directly into working directory and recording every change
+// it makes, so changes are undone if generation fails. Overwritten and removed entries are backed up into
+// directory next to working directory. Shell scripts may change anything, so the whole existing working
+// directory is backed up before the first of them runs and restored on failure. Working directory is removed
+// entirely on failure if generation created it.
+type journal struct {
+	*DirOutput
+
+	// created is true if working directory didn't exist before generation.
+	created bool
+
+	// backups is directory overwritten and removed entries are copied to, it is created on first backup.
+	backups string
+
+	// changes lists changed entries in order of changes, each entry is recorded only once.
+	changes  []journalChange
+	recorded map[string]struct{}
+
+	// tree contains entries of working directory backed up before the first shell script, keyed by paths
+	// relative to working directory. Changes made afterwards aren't recorded, tree is restored instead.
+	tree map[string]fs.FileInfo
+}
+
+// treeBackupDir is directory inside backup directory snapshot of working directory is copied to.
+const treeBackupDir = "tree"
+
+// journalChange describes entry of working directory as it was before generation.
+type journalChange struct {
+	path string
+	// backup is path of copy of entry, it is empty if entry was created by generation.
+	backup string
+}
+
+// stage runs generate writing into working directory wd through journal. Changes are kept if generate
+// succeeds and undone otherwise, unless keepOnFailure is true.
+func stage(wd string, keepOnFailure bool, generate func(j *journal) error) error {
+	j, err := beginJournal(wd)
+	if err != nil {
+		return err
+	}
+
+	if err := generate(j); err != nil {
+		return j.rollback(err, keepOnFailure)
+	}
+
+	return j.commit()
+}
+
+func beginJournal(wd string) (*journal, error) {
+	target, err := filepath.Abs(wd)
+	if err != nil {
+		return nil, fmt.Errorf("resolve working directory %q: %w", wd, err)
+	}
+
+	j := &journal{DirOutput: NewDirOutput(target), recorded: map[string]struct{}{}}
+
+	info, err := os.Stat(target)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		verbose.Printf("creating working directory %q", target)
+		if err := os.MkdirAll(target, dirMode); err != nil {
+			return nil, fmt.Errorf("init dir %q: %w", target, err)
+		}
+		j.created = true
+	case err != nil:
+		return nil, fmt.Errorf("stat %q: %w", target, err)
+	case !info.IsDir():
+		return nil, fmt.Errorf("working directory %q is not a directory", target)
+	}
+
+	return j, nil
+}
+
+func (j *journal) MkdirAll(path string, perm fs.FileMode) error {
+	// only topmost missing directory is recorded, its content is removed along with it
+	var missing string
+	for dir := j.path(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); err == nil || !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+
+		missing = dir
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	if missing != "" {
+		if err := j.record(missing); err != nil {
+			return err
+		}
+	}
+
+	return j.DirOutput.MkdirAll(path, perm)
+}
+
+func (j *journal) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := j.record(j.path(name)); err != nil {
+		return err
+	}
+
+	return j.DirOutput.WriteFile(name, data, perm)
+}
+
+func (j *journal) Chmod(name string, mode fs.FileMode) error {
+	if err := j.record(j.path(name)); err != nil {
+		return err
+	}
+
+	return j.DirOutput.Chmod(name, mode)
+}
+
+func (j *journal) Remove(name string) error {
+	if err := j.record(j.path(name)); err != nil {
+		return err
+	}
+
+	return j.DirOutput.Remove(name)
+}
+
+// record remembers state of entry at path before it is changed for the first time. Existing entry
+// is backed up, missing one is recorded as created.
+func (j *journal) record(path string) error {
+	if j.created || j.tree != nil {
+		return nil
+	}
+
+	if _, ok := j.recorded[path]; ok {
+		return nil
+	}
+
+	change := journalChange{path: path}
+
+	info, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("stat %q: %w", path, err)
+	default:
+		if change.backup, err = j.backup(path, info); err != nil {
+			return fmt.Errorf("back up %q: %w", path, err)
+		}
+	}
+
+	j.recorded[path] = struct{}{}
+	j.changes = append(j.changes, change)
+
+	return nil
+}
+
+// backup copies entry at path into backup directory and returns path of copy. Directory is backed up
+// without its content, as its entries are backed up separately when they are changed.
+func (j *journal) backup(path string, info fs.FileInfo) (string, error) {
+	if err := j.initBackups(); err != nil {
+		return "", err
+	}
+
+	dst := filepath.Join(j.backups, fmt.Sprintf("%d", len(j.changes)))
+	verbose.Printf("backing up %q to %q", path, dst)
+
+	return dst, copyEntry(path, dst, info)
+}
+
+func (j *journal) initBackups() error {
+	if j.backups != "" {
+		return nil
+	}
+
+	parent := filepath.Dir(j.Dir())
+	backups, err := os.MkdirTemp(parent, "."+filepath.Base(j.Dir())+".backup-")
+	if err != nil {
+		return fmt.Errorf("init backup directory: %w", err)
+	}
+	verbose.Printf("backing up changed files of %q in %q", j.Dir(), backups)
+	j.backups = backups
+
+	return nil
+}
+
+// beforeShell backs up the whole working directory before the first shell script runs, as changes made
+// by scripts can't be recorded. Nothing is backed up if generation created working directory.
+// Backing up stops if ctx is done.
+func (j *journal) beforeShell(ctx context.Context) error {
+	if j.created || j.tree != nil {
+		return nil
+	}
+
+	if err := j.initBackups(); err != nil {
+		return err
+	}
+
+	verbose.Printf("backing up %q before running shell scripts", j.Dir())
+
+	tree := map[string]fs.FileInfo{}
+	root := filepath.Join(j.backups, treeBackupDir)
+	err := filepath.WalkDir(j.Dir(), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(j.Dir(), p)
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		tree[rel] = info
+
+		if !isBackedUp(info) {
+			verbose.Printf("%q is not a regular file, skipping", p)
+			return nil
+		}
+
+		if err := copyEntry(p, filepath.Join(root, rel), info); err != nil {
+			return err
+		}
+
+		// restored files keep their modification time
+		if info.Mode().IsRegular() {
+			if err := os.Chtimes(filepath.Join(root, rel), info.ModTime(), info.ModTime()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("back up working directory: %w", err)
+	}
+
+	j.tree = tree
+	return nil
+}
+
+// commit keeps changes and removes backups.
+func (j *journal) commit() error {
+	if j.backups == "" {
+		return nil
+	}
+
+	if err := os.RemoveAll(j.backups); err != nil {
+		return fmt.Errorf("remove backup directory: %w", err)
+	}
+
+	return nil
+}
+
+// rollback undoes recorded changes unless keep is true and returns cause of failure.
+func (j *journal) rollback(cause error, keep bool) error {
+	if keep {
+		if j.backups != "" {
+			return fmt.Errorf(
+				"%w (partially generated project is kept in %q, overwritten files are backed up in %q)",
+				cause,
+				j.Dir(),
+				j.backups,
+			)
+		}
+		return fmt.Errorf("%w (partially generated project is kept in %q)", cause, j.Dir())
+	}
+
+	if j.created {
+		verbose.Printf("generation failed, removing working directory %q", j.Dir())
+		if err := os.RemoveAll(j.Dir()); err != nil {
+			return fmt.Errorf("%w (remove working directory: %v)", cause, err)
+		}
+		return cause
+	}
+
+	verbose.Printf("generation failed, undoing changes in %q", j.Dir())
+	if j.tree != nil {
+		if err := j.restoreTree(); err != nil {
+			return fmt.Errorf("%w (restore working directory: %v, backups are kept in %q)", cause, err, j.backups)
+		}
+	}
+
+	for i := len(j.changes) - 1; i >= 0; i-- {
+		if err := j.changes[i].undo(); err != nil {
+			return fmt.Errorf("%w (undo changes: %v, backups are kept in %q)", cause, err, j.backups)
+		}
+	}
+
+	if err := j.commit(); err != nil {
+		return fmt.Errorf("%w (%v)", cause, err)
+	}
+
+	return cause
+}
+
+// restoreTree brings working directory back to state it had before the first shell script: entries
+// created since then are removed and changed ones are restored from backup. Entries are compared
+// by content, so entries left untouched keep their modification times.
+func (j *journal) restoreTree() error {
+	root := filepath.Join(j.backups, treeBackupDir)
+
+	err := filepath.WalkDir(j.Dir(), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(j.Dir(), p)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := j.tree[rel]; ok {
+			return nil
+		}
+
+		verbose.Printf("removing %q", p)
+		if err := os.RemoveAll(p); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(j.tree))
+	for rel := range j.tree {
+		paths = append(paths, rel)
+	}
+	// parent directories precede their content
+	sort.Strings(paths)
+
+	for _, rel := range paths {
+		if err := restoreEntry(filepath.Join(root, rel), filepath.Join(j.Dir(), rel), j.tree[rel]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreEntry replaces entry at dst with its backup at src unless they are equal.
+func restoreEntry(src, dst string, info fs.FileInfo) error {
+	if !isBackedUp(info) {
+		return nil
+	}
+
+	current, err := os.Lstat(dst)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if err == nil {
+		same, err := isSameEntry(src, dst, info, current)
+		if err != nil || same {
+			return err
+		}
+	}
+
+	if info.IsDir() {
+		if current != nil && !current.IsDir() {
+			if err := os.Remove(dst); err != nil {
+				return err
+			}
+		}
+
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return os.Chmod(dst, info.Mode().Perm())
+	}
+
+	verbose.Printf("restoring %q", dst)
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+
+	return os.Rename(src, dst)
+}
+
+// isSameEntry reports whether entry current at dst has the same type, permissions and content as backup
+// at src of entry described by info.
+func isSameEntry(src, dst string, info, current fs.FileInfo) (bool, error) {
+	if info.Mode() != current.Mode() {
+		return false, nil
+	}
+
+	switch {
+	case info.IsDir():
+		return true, nil
+	case info.Mode()&fs.ModeSymlink != 0:
+		before, err := os.Readlink(src)
+		if err != nil {
+			return false, err
+		}
+		after, err := os.Readlink(dst)
+		return before == after, err
+	}
+
+	if info.Size() != current.Size() {
+		return false, nil
+	}
+
+	before, err := os.ReadFile(src)
+	if err != nil {
+		return false, err
+	}
+
+	after, err := os.ReadFile(dst)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(before, after), nil
+}
+
+// undo restores entry from backup or removes it if it was created.
+func (c journalChange) undo() error {
+	verbose.Printf("restoring %q", c.path)
+
+	if c.backup == "" {
+		return os.RemoveAll(c.path)
+	}
+
+	info, err := os.Lstat(c.backup)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		if err := os.MkdirAll(c.path, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return os.Chmod(c.path, info.Mode().Perm())
+	}
+
+	if err := os.RemoveAll(c.path); err != nil {
+		return err
+	}
+
+	return os.Rename(c.backup, c.path)
+}
+
+// isBackedUp reports whether entry is file, directory or symbolic link, other special files
+// (e.g. sockets) aren't backed up.
+func isBackedUp(info fs.FileInfo) bool {
+	return info.IsDir() || info.Mode().IsRegular() || info.Mode()&fs.ModeSymlink != 0
+}
+
+// copyEntry copies file, directory or symbolic link from src to dst keeping permission bits.
+// Directories are copied without their content.
+func copyEntry(src, dst string, info fs.FileInfo) error {
+	switch {
+	case info.IsDir():
+		if err := os.Mkdir(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		// umask may drop permission bits on creation
+		return os.Chmod(dst, info.Mode().Perm())
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case !isBackedUp(info):
+		return fmt.Errorf("%q is not a regular file", src)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Chmod(dst, info.Mode().Perm())
+}
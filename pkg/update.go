@@ -0,0 +1,345 @@
+package projector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/tomakado/projector/internal/pkg/merge"
+	"github.com/tomakado/projector/internal/pkg/verbose"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+// UpdateConfig describes update of previously generated project onto newer version of its templates.
+type UpdateConfig struct {
+	// WorkingDirectory is root of project containing answers file.
+	WorkingDirectory string
+
+	// Answers are inputs project was generated with.
+	Answers *Answers
+
+	// From contains templates project was generated from and To contains templates to update project to.
+	// Both are in order of Answers.Templates.
+	From []Template
+	To   []Template
+
+	// Vars overrides values of variables recorded in answers, e.g. for variables introduced by new version.
+	Vars map[string]string
+
+	// Resolver locates templates extended by manifests by name, see CreateConfig.
+	Resolver manifest.Resolver
+}
+
+// UpdateResult describes changes made to project by Update. Paths are relative to working directory.
+type UpdateResult struct {
+	// Added contains files introduced by new version of templates.
+	Added []string
+
+	// Updated contains files changed by new version and merged with local changes without conflicts.
+	Updated []string
+
+	// Conflicted contains files changed both locally and by new version. Conflicts are left
+	// between conflict markers.
+	Conflicted []string
+
+	// Removed contains files deleted from templates and not modified locally.
+	Removed []string
+
+	// Skipped contains files changed by new version but deleted locally, files deleted from templates
+	// but modified locally and binary files modified on both sides. They are left untouched.
+	Skipped []string
+
+	// Commands contains shell scripts of new version absent in old one. They are not executed.
+	Commands []string
+}
+
+// rendering is project rendered in memory.
+type rendering struct {
+	files     map[string][]byte
+	modes     map[string]fs.FileMode
+	labels    map[string]string
+	commands  []string
+	vars      map[string]interface{}
+	templates []loadedTemplate
+}
+
+// Update renders old and new versions of templates with recorded answers and applies difference between them
+// onto working directory using three-way merge, so local changes are kept. Answers file is updated
+// to record new versions of templates. Changes are applied as transaction, so working directory is left
+// untouched if ctx is done before rendering completes or applying of any file fails.
+func Update(ctx context.Context, cfg UpdateConfig) (*UpdateResult, error) {
+	if len(cfg.From) != len(cfg.Answers.Templates) || len(cfg.To) != len(cfg.Answers.Templates) {
+		return nil, fmt.Errorf(
+			"expected %d templates to update from and to, got %d and %d",
+			len(cfg.Answers.Templates), len(cfg.From), len(cfg.To),
+		)
+	}
+
+	verbose.Println("rendering templates project was generated from")
+	base, err := render(ctx, cfg, cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("render old version: %w", err)
+	}
+
+	for i, t := range base.templates {
+		if recorded := cfg.Answers.Templates[i].Version; t.manifest.Version != recorded {
+			return nil, fmt.Errorf(
+				"template %q has version %s, but project was generated from version %s",
+				t.manifest.Name, t.manifest.Version, recorded,
+			)
+		}
+	}
+
+	verbose.Println("rendering new version of templates")
+	theirs, err := render(ctx, cfg, cfg.To)
+	if err != nil {
+		return nil, fmt.Errorf("render new version: %w", err)
+	}
+
+	var (
+		result UpdateResult
+		paths  = map[string]struct{}{}
+	)
+
+	for p := range base.files {
+		paths[p] = struct{}{}
+	}
+
+	for p := range theirs.files {
+		paths[p] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	executed := map[string]struct{}{}
+	for _, c := range base.commands {
+		executed[c] = struct{}{}
+	}
+
+	for _, c := range theirs.commands {
+		if _, ok := executed[c]; !ok {
+			result.Commands = append(result.Commands, c)
+		}
+	}
+
+	answers := newAnswers(&Config{
+		ProjectName:    cfg.Answers.ProjectName,
+		ProjectPackage: cfg.Answers.ProjectPackage,
+		ProjectAuthor:  cfg.Answers.ProjectAuthor,
+		OptionalSteps:  cfg.Answers.OptionalSteps,
+		Vars:           theirs.vars,
+	}, theirs.templates)
+
+	// answers are saved along with files, so failed update can be run again against the same base
+	err = stage(cfg.WorkingDirectory, false, func(j *journal) error {
+		for _, p := range sorted {
+			if err := applyFile(j, p, base, theirs, &result); err != nil {
+				return err
+			}
+		}
+
+		return answers.save(j, AnswersFilename)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// applyFile merges changes of single file between base and new rendering into output of working directory.
+func applyFile(output Output, p string, base, theirs *rendering, result *UpdateResult) error {
+	var (
+		baseData, inBase     = base.files[p]
+		theirsData, inTheirs = theirs.files[p]
+		oursData, readErr    = output.ReadFile(p)
+		inOurs               = readErr == nil
+		isChangedByTemplate  = !inBase || !bytes.Equal(baseData, theirsData)
+		isChangedLocally     = !inBase || !bytes.Equal(baseData, oursData)
+		isAlreadyUpToDate    = inOurs && inTheirs && bytes.Equal(oursData, theirsData)
+	)
+
+	if readErr != nil && !errors.Is(readErr, fs.ErrNotExist) {
+		return fmt.Errorf("read %q: %w", p, readErr)
+	}
+
+	switch {
+	case isAlreadyUpToDate:
+		return updateMode(output, p, base, theirs)
+	case !inTheirs:
+		if !inOurs {
+			return nil
+		}
+
+		if isChangedLocally {
+			verbose.Printf("%q is removed from template but modified locally, keeping it", p)
+			result.Skipped = append(result.Skipped, p)
+			return nil
+		}
+
+		verbose.Printf("removing %q", p)
+		if err := output.Remove(p); err != nil {
+			return fmt.Errorf("remove %q: %w", p, err)
+		}
+		result.Removed = append(result.Removed, p)
+	case !inOurs:
+		if inBase {
+			if isChangedByTemplate {
+				verbose.Printf("%q is changed by template but deleted locally, keeping it deleted", p)
+				result.Skipped = append(result.Skipped, p)
+			}
+			return nil
+		}
+
+		verbose.Printf("adding %q", p)
+		if err := output.MkdirAll(filepath.Dir(p), dirMode); err != nil {
+			return fmt.Errorf("init dir of %q: %w", p, err)
+		}
+
+		if err := output.WriteFile(p, theirsData, theirs.modes[p]); err != nil {
+			return fmt.Errorf("write %q: %w", p, err)
+		}
+		result.Added = append(result.Added, p)
+	case !isChangedByTemplate:
+		return updateMode(output, p, base, theirs)
+	case isBinary(oursData) || isBinary(theirsData):
+		if isChangedLocally {
+			verbose.Printf("binary %q is modified on both sides, keeping local version", p)
+			result.Skipped = append(result.Skipped, p)
+			return nil
+		}
+
+		if err := writeExisting(output, p, theirsData, base, theirs); err != nil {
+			return err
+		}
+		result.Updated = append(result.Updated, p)
+	default:
+		merged, conflicts := merge.Merge(baseData, oursData, theirsData, merge.Labels{
+			Ours:   "local",
+			Theirs: theirs.labels[p],
+		})
+
+		if err := writeExisting(output, p, merged, base, theirs); err != nil {
+			return err
+		}
+
+		if conflicts > 0 {
+			verbose.Printf("%q has %d conflicts", p, conflicts)
+			result.Conflicted = append(result.Conflicted, p)
+			return nil
+		}
+
+		result.Updated = append(result.Updated, p)
+	}
+
+	return nil
+}
+
+// writeExisting replaces content of existing file. Its mode is kept unless new version changes it.
+func writeExisting(output Output, p string, data []byte, base, theirs *rendering) error {
+	verbose.Printf("updating %q", p)
+	if err := output.WriteFile(p, data, theirs.modes[p]); err != nil {
+		return fmt.Errorf("write %q: %w", p, err)
+	}
+
+	return updateMode(output, p, base, theirs)
+}
+
+// updateMode sets mode of file declared by new version if it differs from mode of old version,
+// e.g. if script becomes executable. Local mode is kept otherwise.
+func updateMode(output Output, p string, base, theirs *rendering) error {
+	baseMode, inBase := base.modes[p]
+	theirsMode, inTheirs := theirs.modes[p]
+	if !inBase || !inTheirs || baseMode == theirsMode {
+		return nil
+	}
+
+	verbose.Printf("chmod %o %s", theirsMode, p)
+	if err := output.Chmod(p, theirsMode); err != nil {
+		return fmt.Errorf("set mode of %q: %w", p, err)
+	}
+
+	return nil
+}
+
+// render generates project from templates into memory using recorded answers.
+// Values of variables not declared by templates are ignored, as versions may declare different variables.
+func render(ctx context.Context, cfg UpdateConfig, templates []Template) (*rendering, error) {
+	declared, err := loadTemplates(ctx, cfg.Resolver, templates)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		vars     = map[string]string{}
+		passed   = cfg.Answers.RawVars()
+		combined = combineManifests(declared)
+	)
+
+	for name, value := range cfg.Vars {
+		passed[name] = value
+	}
+
+	for _, v := range combined.Variables {
+		if value, ok := passed[v.Name]; ok {
+			vars[v.Name] = value
+		}
+	}
+
+	var (
+		output = NewMemOutput()
+		config = &Config{
+			WorkingDirectory: cfg.WorkingDirectory,
+			ProjectName:      cfg.Answers.ProjectName,
+			ProjectPackage:   cfg.Answers.ProjectPackage,
+			ProjectAuthor:    cfg.Answers.ProjectAuthor,
+			OptionalSteps:    cfg.Answers.OptionalSteps,
+			DryRun:           true,
+			memory:           output,
+		}
+	)
+
+	result, loaded, err := create(ctx, CreateConfig{
+		Config:         config,
+		Provider:       templates[0].Provider,
+		PathToManifest: templates[0].PathToManifest,
+		Source:         templates[0].Source,
+		Revision:       templates[0].Revision,
+		Vars:           vars,
+		With:           templates[1:],
+		Resolver:       cfg.Resolver,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &rendering{
+		files:     output.Files(),
+		modes:     map[string]fs.FileMode{},
+		labels:    map[string]string{},
+		commands:  result.Commands,
+		vars:      config.Vars,
+		templates: loaded,
+	}
+
+	versions := map[string]string{}
+	for _, t := range loaded {
+		versions[t.manifest.Name] = t.manifest.Version
+	}
+
+	for _, f := range result.Files {
+		p := filepath.Clean(f.Path)
+		r.modes[p] = f.Mode
+		r.labels[p] = fmt.Sprintf("%s@%s", f.Template, versions[f.Template])
+	}
+
+	return r, nil
+}
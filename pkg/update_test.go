@@ -0,0 +1,147 @@
+package projector_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+// updateVersions returns versions of "update" testdata template and creates project of the first one in wd.
+func updateVersions(t *testing.T, wd string) (projector.Template, projector.Template) {
+	t.Helper()
+
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	var (
+		p  = manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata", "update"))
+		v1 = projector.Template{Provider: p, PathToManifest: "1.0.0", Source: "update"}
+		v2 = projector.Template{Provider: p, PathToManifest: "1.1.0", Source: "update"}
+	)
+
+	_, err = projector.Create(context.Background(), projector.CreateConfig{
+		Config:         &projector.Config{WorkingDirectory: wd, ProjectName: "app", ProjectAuthor: "tomakado"},
+		Provider:       v1.Provider,
+		PathToManifest: v1.PathToManifest,
+		Source:         v1.Source,
+	})
+	require.NoError(t, err)
+
+	return v1, v2
+}
+
+func TestUpdate(t *testing.T) {
+	wd := t.TempDir()
+	v1, v2 := updateVersions(t, wd)
+
+	// local changes made after generation
+	writeFile(t, wd, "README.md", "# app\n\nMy service.\n\n## Usage\n\nRun the app.\n")
+	writeFile(t, wd, "Makefile", "build:\n\tgo build -race ./...\n")
+	writeFile(t, wd, "NOTES.md", "my notes\n")
+	require.NoError(t, os.Remove(filepath.Join(wd, "main.go")))
+
+	answers, err := projector.LoadAnswers(filepath.Join(wd, projector.AnswersFilename))
+	require.NoError(t, err)
+
+	t.Run("old version must match answers", func(t *testing.T) {
+		_, err := projector.Update(context.Background(), projector.UpdateConfig{
+			WorkingDirectory: wd,
+			Answers:          answers,
+			From:             []projector.Template{v2},
+			To:               []projector.Template{v2},
+		})
+		require.Error(t, err)
+	})
+
+	result, err := projector.Update(context.Background(), projector.UpdateConfig{
+		WorkingDirectory: wd,
+		Answers:          answers,
+		From:             []projector.Template{v1},
+		To:               []projector.Template{v2},
+		Vars:             map[string]string{"port": "9090"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, &projector.UpdateResult{
+		Added:      []string{"Dockerfile"},
+		Updated:    []string{"README.md"},
+		Conflicted: []string{"Makefile"},
+		Removed:    []string{"LICENSE"},
+		Skipped:    []string{"NOTES.md", "main.go"},
+		Commands:   []string{"go mod tidy"},
+	}, result)
+
+	expectedFiles := map[string]string{
+		"README.md":  "# app\n\nMy service.\n\n## Usage\n\nRun the app with `make run`.\n",
+		"Makefile":   "build:\n<<<<<<< local\n\tgo build -race ./...\n=======\n\tgo build -o bin/app ./...\n>>>>>>> update@1.1.0\n",
+		"Dockerfile": "FROM golang\nEXPOSE 9090\n",
+		"NOTES.md":   "my notes\n",
+	}
+
+	for name, expected := range expectedFiles {
+		actual, err := os.ReadFile(filepath.Join(wd, name))
+		require.NoError(t, err)
+		require.Equal(t, expected, string(actual), name)
+	}
+
+	for _, name := range []string{"LICENSE", "main.go"} {
+		_, err := os.Stat(filepath.Join(wd, name))
+		require.True(t, os.IsNotExist(err), name)
+	}
+
+	// mode changed by new version is applied to file with unchanged content
+	info, err := os.Stat(filepath.Join(wd, "build.sh"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	updated, err := projector.LoadAnswers(filepath.Join(wd, projector.AnswersFilename))
+	require.NoError(t, err)
+	require.Equal(t, []projector.AnsweredTemplate{{Source: "update", Version: "1.1.0"}}, updated.Templates)
+	require.Equal(t, map[string]string{"port": "9090"}, updated.RawVars())
+}
+
+func TestUpdate_Failed(t *testing.T) {
+	wd := t.TempDir()
+	v1, v2 := updateVersions(t, wd)
+
+	// README.md can't be merged, but Dockerfile, LICENSE and Makefile are applied before it
+	require.NoError(t, os.Remove(filepath.Join(wd, "README.md")))
+	require.NoError(t, os.Mkdir(filepath.Join(wd, "README.md"), 0o755))
+
+	answers, err := projector.LoadAnswers(filepath.Join(wd, projector.AnswersFilename))
+	require.NoError(t, err)
+
+	_, err = projector.Update(context.Background(), projector.UpdateConfig{
+		WorkingDirectory: wd,
+		Answers:          answers,
+		From:             []projector.Template{v1},
+		To:               []projector.Template{v2},
+	})
+	require.Error(t, err)
+
+	_, err = os.Stat(filepath.Join(wd, "Dockerfile"))
+	require.True(t, os.IsNotExist(err), "added file is removed")
+
+	for name, expected := range map[string]string{
+		"LICENSE":  "MIT\n",
+		"Makefile": "build:\n\tgo build ./...\n",
+	} {
+		actual, err := os.ReadFile(filepath.Join(wd, name))
+		require.NoError(t, err)
+		require.Equal(t, expected, string(actual), name)
+	}
+
+	updated, err := projector.LoadAnswers(filepath.Join(wd, projector.AnswersFilename))
+	require.NoError(t, err)
+	require.Equal(t, []projector.AnsweredTemplate{{Source: "update", Version: "1.0.0"}}, updated.Templates)
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
@@ -0,0 +1,235 @@
+package projector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template/parse"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/tomakado/projector/internal/pkg/verbose"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+// ValidateTemplate loads and validates manifest at path like manifest.Load does and statically checks templates
+// of files, output paths, conditions and shell scripts. Templates may reference only fields of Config and
+// variables declared by manifest, so typos are found before any step is executed.
+// Names of extended templates are resolved with resolve, see manifest.Load.
+// Problems are returned as multierror of *manifest.ValidationError.
+func ValidateTemplate(
+	ctx context.Context, p provider, path string, resolve manifest.Resolver,
+) (*manifest.Manifest, error) {
+	m, locator, err := manifest.LoadWithLocator(ctx, p, path, resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	verbose.Println("checking fields referenced by templates")
+
+	l := &linter{provider: p, dir: filepath.Dir(path), manifest: m, locator: locator}
+
+	errs, err := l.fieldErrors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result error
+	for _, e := range errs {
+		result = multierror.Append(result, e)
+	}
+
+	if result != nil {
+		return nil, result
+	}
+
+	return m, nil
+}
+
+// fieldErrors returns errors of templates referencing fields missing in Config or undeclared variables.
+// Errors found in template files are positioned in these files.
+func (l *linter) fieldErrors(ctx context.Context) ([]*manifest.ValidationError, error) {
+	var errs []*manifest.ValidationError
+
+	// checkField reports undefined fields of template in manifest field at position of the field
+	checkField := func(field, text string, delims manifest.Delims) {
+		for _, message := range l.undefinedFields(field, text, delims) {
+			errs = append(errs, l.locator.Error(field, message.text))
+		}
+	}
+
+	for i, step := range l.manifest.Steps {
+		stepDelims := step.Delims.Or(l.manifest.Delims)
+
+		checkField(fmt.Sprintf("steps[%d].when", i), step.When, stepDelims)
+		checkField(fmt.Sprintf("steps[%d].shell", i), step.Shell, stepDelims)
+
+		for j, file := range step.Files {
+			fileDelims := file.Delims.Or(stepDelims)
+
+			checkField(fmt.Sprintf("steps[%d].files[%d].when", i, j), file.When, fileDelims)
+			checkField(fmt.Sprintf("steps[%d].files[%d].output", i, j), file.Output, fileDelims)
+
+			if file.Raw {
+				continue
+			}
+
+			paths, err := l.templateFiles(ctx, step, file)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, p := range paths {
+				if l.manifest.IsRaw(p) {
+					continue
+				}
+
+				from := l.stepSource(step)
+				filename := filepath.Join(from.dir, p)
+				src, err := from.provider.Get(ctx, filename)
+				if err != nil {
+					return nil, err
+				}
+
+				if isBinary(src) {
+					continue
+				}
+
+				for _, message := range l.undefinedFields(p, string(src), fileDelims) {
+					e := l.locator.Error(fmt.Sprintf("steps[%d].files[%d].path", i, j), message.text)
+					line, column := textPosition(src, message.offset)
+					e.Position = manifest.Position{Filename: filename, Line: line, Column: column}
+
+					errs = append(errs, e)
+				}
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+// templateMessage is problem of template at byte offset in its text.
+type templateMessage struct {
+	offset int
+	text   string
+}
+
+// undefinedFields returns messages about fields and variables used by template but missing in generation
+// context. Fields are checked only where dot is generation context, i.e. outside of range and with blocks.
+// Error of template parsing is returned as message too.
+func (l *linter) undefinedFields(name, text string, delims manifest.Delims) []templateMessage {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	t, err := delims.NewTemplate(name).Parse(text)
+	if err != nil {
+		return []templateMessage{{text: fmt.Sprintf("parse template: %s", err)}}
+	}
+
+	var messages []templateMessage
+	walkTemplateFields(t.Tree.Root, true, func(n parse.Node, idents []string) {
+		if message := l.undefinedField(idents); message != "" {
+			messages = append(messages, templateMessage{offset: int(n.Position()), text: message})
+		}
+	})
+
+	return messages
+}
+
+// undefinedField returns message if chain of field names doesn't resolve against Config or if it refers
+// to undeclared variable. Fields of maps, interfaces and method results are not checked.
+func (l *linter) undefinedField(idents []string) string {
+	if len(idents) > 1 && idents[0] == "Vars" {
+		for _, v := range l.manifest.Variables {
+			if v.Name == idents[1] {
+				return ""
+			}
+		}
+
+		return fmt.Sprintf("undefined variable %q", idents[1])
+	}
+
+	t := reflect.TypeOf(Config{})
+	for i, ident := range idents {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct {
+			return ""
+		}
+
+		if _, ok := reflect.PtrTo(t).MethodByName(ident); ok {
+			return ""
+		}
+
+		f, ok := t.FieldByName(ident)
+		if !ok || f.PkgPath != "" {
+			return fmt.Sprintf("undefined field %q", "."+strings.Join(idents[:i+1], "."))
+		}
+
+		t = f.Type
+	}
+
+	return ""
+}
+
+// walkTemplateFields calls visit for each field chain evaluated against root data of template, i.e. fields
+// of dot while it is root data and fields of $.
+func walkTemplateFields(node parse.Node, isDotRoot bool, visit func(parse.Node, []string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplateFields(child, isDotRoot, visit)
+		}
+	case *parse.ActionNode:
+		walkTemplateFields(n.Pipe, isDotRoot, visit)
+	case *parse.IfNode:
+		walkTemplateFields(n.Pipe, isDotRoot, visit)
+		walkTemplateFields(n.List, isDotRoot, visit)
+		walkTemplateFields(n.ElseList, isDotRoot, visit)
+	case *parse.RangeNode:
+		walkTemplateFields(n.Pipe, isDotRoot, visit)
+		walkTemplateFields(n.List, false, visit)
+		walkTemplateFields(n.ElseList, isDotRoot, visit)
+	case *parse.WithNode:
+		walkTemplateFields(n.Pipe, isDotRoot, visit)
+		walkTemplateFields(n.List, false, visit)
+		walkTemplateFields(n.ElseList, isDotRoot, visit)
+	case *parse.TemplateNode:
+		walkTemplateFields(n.Pipe, isDotRoot, visit)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkTemplateFields(cmd, isDotRoot, visit)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkTemplateFields(arg, isDotRoot, visit)
+		}
+	case *parse.ChainNode:
+		walkTemplateFields(n.Node, isDotRoot, visit)
+	case *parse.FieldNode:
+		if isDotRoot {
+			visit(n, n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			visit(n, n.Ident[1:])
+		}
+	}
+}
+
+// textPosition converts byte offset in text into line and column starting at 1.
+func textPosition(text []byte, offset int) (int, int) {
+	before := string(text[:offset])
+	return strings.Count(before, "\n") + 1, offset - strings.LastIndex(before, "\n")
+}
@@ -0,0 +1,78 @@
+package projector_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	p := manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata"))
+
+	type testCase struct {
+		name           string
+		path           string
+		isValid        bool
+		expectedErrors []string
+	}
+
+	testCases := []testCase{
+		{
+			name:    "template references only existing fields",
+			path:    "dry-run/projector.toml",
+			isValid: true,
+		},
+		{
+			name: "undefined fields and variables",
+			path: "lint/projector.toml",
+			expectedErrors: []string{
+				`lint/projector.toml:23:1: step "bootstrap": undefined variable "docker"`,
+				`lint/main.go.tpl:6:24: step "bootstrap": file #1: undefined field ".Manifest.Versoin"`,
+				`lint/main.go.tpl:8:57: step "bootstrap": file #1: undefined variable "dbb"`,
+				`lint/projector.toml:30:2: step "bootstrap": file #2: undefined field ".ProjctName"`,
+			},
+		},
+		{
+			name: "invalid manifest",
+			path: "lint/templates/projector.toml",
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := projector.ValidateTemplate(context.Background(), p, tc.path, nil)
+
+			if tc.isValid {
+				require.NoError(t, err)
+				require.NotNil(t, m)
+				return
+			}
+
+			require.Error(t, err)
+			require.Nil(t, m)
+
+			if tc.expectedErrors == nil {
+				return
+			}
+
+			var verr *manifest.ValidationError
+			require.True(t, errors.As(err, &verr))
+
+			var actualErrors []string
+			for _, e := range manifest.ValidationErrors(err) {
+				actualErrors = append(actualErrors, e.Error())
+			}
+			require.Equal(t, tc.expectedErrors, actualErrors)
+		})
+	}
+}
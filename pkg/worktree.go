@@ -0,0 +1,63 @@
+package projector
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomakado/projector/internal/pkg/verbose"
+)
+
+// GitWorktreeOutput writes generated project into new worktree of git repository checked out on new branch,
+// so project can be reviewed and committed without touching main worktree of repository.
+type GitWorktreeOutput struct {
+	*DirOutput
+
+	branch string
+}
+
+// NewGitWorktreeOutput adds worktree of repo in dir with new branch created from HEAD of repo.
+// Dir must not exist or must be empty.
+func NewGitWorktreeOutput(ctx context.Context, repo, dir, branch string) (*GitWorktreeOutput, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve worktree directory %q: %w", dir, err)
+	}
+
+	verbose.Printf("adding worktree %q of %q on branch %q", dir, repo, branch)
+	if err := runGit(ctx, repo, "worktree", "add", "--quiet", "-b", branch, dir); err != nil {
+		return nil, err
+	}
+
+	return &GitWorktreeOutput{DirOutput: NewDirOutput(dir), branch: branch}, nil
+}
+
+// Branch returns name of branch checked out in worktree.
+func (o *GitWorktreeOutput) Branch() string {
+	return o.branch
+}
+
+// Commit stages all changes of worktree and commits them to branch of worktree.
+func (o *GitWorktreeOutput) Commit(ctx context.Context, message string) error {
+	verbose.Printf("committing generated project to branch %q", o.branch)
+
+	if err := runGit(ctx, o.Dir(), "add", "--all"); err != nil {
+		return err
+	}
+
+	return runGit(ctx, o.Dir(), "commit", "--quiet", "--message", message)
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	output, err := runCommand(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package projector_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tomakado/projector/internal/pkg/gittest"
+	projector "github.com/tomakado/projector/pkg"
+	"github.com/tomakado/projector/pkg/manifest"
+)
+
+func TestCreate_GitWorktree(t *testing.T) {
+	gittest.SkipIfNoGit(t)
+
+	for name, value := range map[string]string{
+		"GIT_AUTHOR_NAME":     "test",
+		"GIT_COMMITTER_NAME":  "test",
+		"GIT_AUTHOR_EMAIL":    "test@test",
+		"GIT_COMMITTER_EMAIL": "test@test",
+	} {
+		setenv(t, name, value)
+	}
+
+	startWorkingDirectory, err := os.Getwd()
+	require.NoError(t, err)
+
+	var (
+		ctx  = context.Background()
+		repo = gittest.NewBareRepo(t)
+		dir  = filepath.Join(t.TempDir(), "scaffold")
+	)
+
+	output, err := projector.NewGitWorktreeOutput(ctx, repo.Bare, dir, "scaffold")
+	require.NoError(t, err)
+	require.Equal(t, "scaffold", output.Branch())
+
+	_, err = projector.Create(ctx, projector.CreateConfig{
+		Config: &projector.Config{
+			WorkingDirectory: dir,
+			ProjectName:      "app",
+			ProjectAuthor:    "tomakado",
+			Output:           output,
+		},
+		Provider:       manifest.NewRealFSProvider(filepath.Join(startWorkingDirectory, "testdata")),
+		PathToManifest: "archive",
+		SkipAnswers:    true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, output.Commit(ctx, "generate project"))
+
+	files, err := exec.Command("git", "-C", repo.Bare, "ls-tree", "-r", "--name-only", "scaffold").CombinedOutput()
+	require.NoError(t, err, string(files))
+	require.Equal(t, []string{"README.md", "bin/run.sh", "secret.env", "tpl/hello.txt"}, strings.Fields(string(files)))
+
+	_, err = projector.NewGitWorktreeOutput(ctx, repo.Bare, filepath.Join(t.TempDir(), "again"), "scaffold")
+	require.Error(t, err, "branch already exists")
+}
+
+// setenv sets environment variable for duration of test.
+func setenv(t *testing.T, name, value string) {
+	t.Helper()
+
+	previous, isSet := os.LookupEnv(name)
+	require.NoError(t, os.Setenv(name, value))
+	t.Cleanup(func() {
+		if isSet {
+			_ = os.Setenv(name, previous)
+			return
+		}
+		_ = os.Unsetenv(name)
+	})
+}